@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCacheKey_deterministic(t *testing.T) {
@@ -26,6 +28,60 @@ func TestCacheKey_deterministic(t *testing.T) {
 	if CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: false}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true}, nil) {
 		t.Error("includeChildren flag should affect cache key")
 	}
+	// Child depth affects key; --children alone is depth 1
+	if CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true}, nil) != CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true, ChildDepth: 1}, nil) {
+		t.Error("--children should match --depth 1")
+	}
+	if CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true, ChildDepth: 3}, nil) {
+		t.Error("ChildDepth should affect cache key")
+	}
+	// Child discovery config affects key only when children are loaded
+	withChildren := &ReportConfig{JQLQuery: "project = X", IncludeChildren: true}
+	if CacheKey(withChildren, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true, ChildLinkTypes: []string{"implements"}}, nil) {
+		t.Error("ChildLinkTypes should affect cache key")
+	}
+	if CacheKey(withChildren, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true, NoChildIssuesOf: true}, nil) {
+		t.Error("NoChildIssuesOf should affect cache key")
+	}
+	if CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) != CacheKey(&ReportConfig{JQLQuery: "project = X", HierarchyFieldNames: []string{"Feature Link"}}, nil) {
+		t.Error("hierarchy fields should not affect the key when children are not loaded")
+	}
+	// Extra fields affect key regardless of order
+	if CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", ExtraFields: []string{"labels"}}, nil) {
+		t.Error("ExtraFields should affect cache key")
+	}
+	if CacheKey(&ReportConfig{ExtraFields: []string{"labels", "Sprint"}}, nil) != CacheKey(&ReportConfig{ExtraFields: []string{"Sprint", "labels"}}, nil) {
+		t.Error("ExtraFields order should not affect cache key")
+	}
+	// Issue links are keyed apart from a child link type named "1"
+	if CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true, LoadIssueLinks: true}, nil) ==
+		CacheKey(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true, ChildLinkTypes: []string{"1"}}, nil) {
+		t.Error("LoadIssueLinks should not collide with a child link type")
+	}
+	// Custom fields selected by --columns are cached in Extra too; built-in columns are not
+	if CacheKey(&ReportConfig{JQLQuery: "project = X", Columns: []string{"key", "Epic Link"}}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", Columns: []string{"key"}}, nil) {
+		t.Error("custom field columns should affect cache key")
+	}
+	if CacheKey(&ReportConfig{JQLQuery: "project = X", Columns: []string{"key", "due"}}, nil) != CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) {
+		t.Error("built-in columns should not affect cache key")
+	}
+	// Result cap affects key; negative caps mean unlimited like 0
+	if CacheKey(&ReportConfig{JQLQuery: "project = X", MaxResults: 1000}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) {
+		t.Error("MaxResults should affect cache key")
+	}
+	if CacheKey(&ReportConfig{JQLQuery: "project = X", MaxResults: -1}, nil) != CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) {
+		t.Error("all unlimited MaxResults values should share a cache key")
+	}
+	if CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", LoadChangelog: true}, nil) {
+		t.Error("LoadChangelog should affect cache key")
+	}
+	// Status map file affects key (it changes canonical statuses and trending)
+	if CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", StatusMapDigest: "abc"}, nil) {
+		t.Error("StatusMapDigest should affect cache key")
+	}
+	if CacheKey(&ReportConfig{JQLQuery: "project = X"}, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", TrendingRulesDigest: "abc"}, nil) {
+		t.Error("TrendingRulesDigest should affect cache key")
+	}
 	// Custom field config affects key
 	base := &ReportConfig{JQLQuery: "project = X"}
 	if CacheKey(base, nil) == CacheKey(&ReportConfig{JQLQuery: "project = X", DueDateFieldName: "Planned end"}, nil) {
@@ -59,6 +115,45 @@ func TestWriteReadCache_roundtrip(t *testing.T) {
 	if len(p2[0].Children) != 1 || p2[0].Children[0].Key != "C-1" {
 		t.Errorf("nested children mismatch: got %+v", p2[0].Children)
 	}
+
+	if err := writeIssueCacheFile(path, issueCacheFile{ParentIssues: parent, Truncated: true}); err != nil {
+		t.Fatalf("writeIssueCacheFile: %v", err)
+	}
+	ent, err := readIssueCacheFile(path)
+	if err != nil || !ent.Truncated {
+		t.Errorf("truncated flag should round-trip: ent=%+v err=%v", ent, err)
+	}
+}
+
+// TestFetchReportIssues_diffSkipsCache verifies that diff never diffs against cached data, which would
+// be the data behind the newest snapshot and so always show no changes.
+func TestFetchReportIssues_diffSkipsCache(t *testing.T) {
+	useTempSnapshotDir(t)
+	if err := reportCache.EnsureDir(); err != nil {
+		t.Fatalf("EnsureDir: %v", err)
+	}
+	issueKeys := []string{"P-1"}
+	cfg := &ReportConfig{Title: "Test"}
+	cached := []*IssueData{{Key: "P-1", Summary: "Cached issue", Trending: "on track"}}
+	path, err := reportCache.Path(CacheKey(cfg, issueKeys))
+	if err != nil {
+		t.Fatalf("Path: %v", err)
+	}
+	if err := writeIssueCache(path, cached); err != nil {
+		t.Fatalf("writeIssueCache: %v", err)
+	}
+	if _, err := saveSnapshot(cfg, issueKeys, cached, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("saveSnapshot: %v", err)
+	}
+
+	base, err := findSnapshot(cfg, issueKeys, "last", time.Now())
+	if err != nil {
+		t.Fatalf("findSnapshot: %v", err)
+	}
+	cfg.DiffBaseline = &base
+	if _, err := FetchReportIssues(context.Background(), nil, issueKeys, cfg); err != ErrCacheMiss {
+		t.Errorf("diff should bypass the primed cache and need Jira, got err %v", err)
+	}
 }
 
 // TestFetchReportIssues_usesCache verifies that FetchReportIssues returns cached data when the
@@ -93,7 +188,7 @@ func TestFetchReportIssues_usesCache(t *testing.T) {
 	}
 
 	// FetchReportIssues with nil client should hit the cache
-	gotParent, err := FetchReportIssues(nil, issueKeys, cfg)
+	gotParent, err := FetchReportIssues(context.Background(), nil, issueKeys, cfg)
 	if err != nil {
 		t.Fatalf("FetchReportIssues (cache hit): %v", err)
 	}
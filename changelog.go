@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const changelogBatchSize = 50
+
+// FieldChange is one changelog transition of a tracked field. Dates are YYYY-MM-DD when they parse;
+// statuses are Jira status names. An empty From or To means the field was unset.
+type FieldChange struct {
+	At   string `json:"at"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// flattenIssues returns issues and all of their loaded descendants, each once.
+func flattenIssues(issues []*IssueData) []*IssueData {
+	seen := make(map[*IssueData]struct{})
+	var out []*IssueData
+	var walk func([]*IssueData)
+	walk = func(list []*IssueData) {
+		for _, issue := range list {
+			if issue == nil {
+				continue
+			}
+			if _, ok := seen[issue]; ok {
+				continue
+			}
+			seen[issue] = struct{}{}
+			out = append(out, issue)
+			walk(issue.Children)
+		}
+	}
+	walk(issues)
+	return out
+}
+
+// loadChangelogs fetches the changelog of every issue (including loaded children) in batches of
+// changelogBatchSize, bounded by MaxConcurrent like loadComments, and fills DueHistory, StatusHistory,
+// DueSlips and OriginalDue.
+func (c *JiraClient) loadChangelogs(ctx context.Context, issues []*IssueData) error {
+	all := flattenIssues(issues)
+	result := make(map[string][]map[string]any, len(all))
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, c.concurrencyCap())
+	for i := 0; i < len(all); i += changelogBatchSize {
+		end := min(i+changelogBatchSize, len(all))
+		batch := append([]*IssueData(nil), all[i:end]...)
+		wg.Add(1)
+		go func(batch []*IssueData) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			batchResult, err := c.getChangelogsBulk(ctx, batch)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			for k, histories := range batchResult {
+				result[k] = histories
+			}
+		}(batch)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	dueFieldID, dueFieldName := "", ""
+	if c.dueDateFieldName != "" {
+		dueFieldName = c.dueDateFieldName
+		dueFieldID = c.customFieldNameToID[c.dueDateFieldName]
+	}
+	for _, issue := range all {
+		applyChangelog(issue, result[issue.Key], dueFieldID, dueFieldName)
+	}
+	logInfo("Loaded changelogs for %d issues", len(all))
+	return nil
+}
+
+// getChangelogsBulk searches one batch with expand=changelog and returns histories by issue key.
+// Jira Cloud embeds at most 100 histories per issue in search results; longer changelogs are
+// re-read in full from /issue/{key}/changelog.
+func (c *JiraClient) getChangelogsBulk(ctx context.Context, issues []*IssueData) (map[string][]map[string]any, error) {
+	quoted := make([]string, len(issues))
+	for i, k := range issues {
+		quoted[i] = fmt.Sprintf("%q", k.Key)
+	}
+	jql := "key in (" + strings.Join(quoted, ",") + ")"
+
+	responseIssues, _, err := c.searchPagesExpand(ctx, jql, "status", "changelog", len(issues))
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]map[string]any, len(responseIssues))
+	for _, issue := range responseIssues {
+		key := getString(issue, "key", "")
+		changelog := getMap(issue, "changelog")
+		histories := getMapList(changelog, "histories")
+		if total := getInt(changelog, "total"); c.IsCloud && total > len(histories) {
+			full, err := c.getIssueChangelog(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			histories = full
+		}
+		result[key] = histories
+	}
+	return result, nil
+}
+
+// getIssueChangelog pages through GET /issue/{key}/changelog (Jira Cloud).
+func (c *JiraClient) getIssueChangelog(ctx context.Context, key string) ([]map[string]any, error) {
+	var all []map[string]any
+	for {
+		params := map[string]string{
+			"startAt":    fmt.Sprintf("%d", len(all)),
+			"maxResults": fmt.Sprintf("%d", defaultPageSize),
+		}
+		response, err := c.getJson(ctx, fmt.Sprintf("issue/%s/changelog", key), params)
+		if err != nil {
+			return nil, err
+		}
+		values := getMapList(response, "values")
+		all = append(all, values...)
+		isLast, _ := response["isLast"].(bool)
+		if isLast || len(values) == 0 || len(all) >= getInt(response, "total") {
+			return all, nil
+		}
+	}
+}
+
+// applyChangelog records due-date and status transitions from histories (any order) on issue.
+// dueFieldID/dueFieldName name the custom due-date field; both empty means the native duedate.
+func applyChangelog(issue *IssueData, histories []map[string]any, dueFieldID, dueFieldName string) {
+	sorted := append([]map[string]any(nil), histories...)
+	at := func(h map[string]any) time.Time {
+		t, _ := ParseJiraDate(getString(h, "created", ""))
+		return t
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return at(sorted[i]).Before(at(sorted[j])) })
+
+	isDue := func(item map[string]any) bool {
+		field, fieldID := getString(item, "field", ""), getString(item, "fieldId", "")
+		if dueFieldID == "" && dueFieldName == "" {
+			return fieldID == "duedate" || strings.EqualFold(field, "duedate")
+		}
+		return (dueFieldID != "" && fieldID == dueFieldID) || (dueFieldName != "" && field == dueFieldName)
+	}
+
+	issue.DueHistory, issue.StatusHistory = nil, nil
+	for _, h := range sorted {
+		created := getString(h, "created", "")
+		for _, item := range getMapList(h, "items") {
+			switch {
+			case isDue(item):
+				issue.DueHistory = append(issue.DueHistory, FieldChange{
+					At:   created,
+					From: changelogDate(item, "from"),
+					To:   changelogDate(item, "to"),
+				})
+			case getString(item, "field", "") == "status":
+				issue.StatusHistory = append(issue.StatusHistory, FieldChange{
+					At:   created,
+					From: getString(item, "fromString", ""),
+					To:   getString(item, "toString", ""),
+				})
+			}
+		}
+	}
+	slips, original := dueSlips(issue.DueHistory, issue.Due)
+	issue.DueSlips, issue.OriginalDue = &slips, original
+}
+
+// dueSlipCount returns the issue's due date slips, or 0 when its changelog was not loaded.
+func dueSlipCount(issue *IssueData) int {
+	if issue.DueSlips == nil {
+		return 0
+	}
+	return *issue.DueSlips
+}
+
+// dueSlips counts changes that moved the due date later and returns the first due date ever set
+// (current when the date never changed).
+func dueSlips(history []FieldChange, current string) (slips int, original string) {
+	for _, ch := range history {
+		if original == "" {
+			original = ch.From
+			if original == "" {
+				original = ch.To
+			}
+		}
+		if ch.From != "" && ch.To != "" && ch.To > ch.From {
+			slips++
+		}
+	}
+	if original == "" {
+		original = changelogDateString(current)
+	}
+	return slips, original
+}
+
+// changelogDate returns a changelog item's raw value (from/to) as YYYY-MM-DD, falling back to its
+// display string (fromString/toString) for custom date fields.
+func changelogDate(item map[string]any, which string) string {
+	for _, k := range []string{which, which + "String"} {
+		if v := strings.TrimSpace(getString(item, k, "")); v != "" {
+			return changelogDateString(v)
+		}
+	}
+	return ""
+}
+
+// changelogDateString normalizes the date formats Jira uses in changelogs to YYYY-MM-DD, or returns s trimmed.
+func changelogDateString(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05.0", "2/Jan/06", "02/Jan/06"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	if t, err := ParseJiraDate(s); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return s
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func changeItem(field, fieldID, from, to, fromString, toString string) map[string]any {
+	return map[string]any{"field": field, "fieldId": fieldID, "from": from, "to": to, "fromString": fromString, "toString": toString}
+}
+
+func history(created string, items ...map[string]any) map[string]any {
+	list := make([]any, len(items))
+	for i, it := range items {
+		list[i] = it
+	}
+	return map[string]any{"created": created, "items": list}
+}
+
+func TestApplyChangelog_nativeDueDate(t *testing.T) {
+	issue := &IssueData{Key: "E-1", Due: "2025-06-01"}
+	histories := []map[string]any{ // deliberately out of order
+		history("2025-03-01T10:00:00.000+0000", changeItem("duedate", "duedate", "2025-04-01", "2025-03-15", "", "")),
+		history("2025-01-01T10:00:00.000+0000",
+			changeItem("duedate", "duedate", "", "2025-02-01", "", ""),
+			changeItem("status", "status", "1", "3", "To Do", "In Progress")),
+		history("2025-02-01T10:00:00.000+0000", changeItem("duedate", "duedate", "2025-02-01", "2025-04-01", "", "")),
+		history("2025-04-01T10:00:00.000+0000", changeItem("duedate", "duedate", "2025-03-15", "2025-06-01", "", "")),
+		history("2025-04-02T10:00:00.000+0000", changeItem("summary", "summary", "", "", "old", "new")),
+	}
+	applyChangelog(issue, histories, "", "")
+
+	if len(issue.DueHistory) != 4 || issue.DueHistory[0].To != "2025-02-01" || issue.DueHistory[3].To != "2025-06-01" {
+		t.Fatalf("DueHistory = %+v", issue.DueHistory)
+	}
+	// 02-01 -> 04-01 and 03-15 -> 06-01 slipped; 04-01 -> 03-15 was pulled in; the first set is not a slip.
+	if dueSlipCount(issue) != 2 {
+		t.Errorf("DueSlips = %d, want 2", dueSlipCount(issue))
+	}
+	if issue.OriginalDue != "2025-02-01" {
+		t.Errorf("OriginalDue = %q, want 2025-02-01", issue.OriginalDue)
+	}
+	if len(issue.StatusHistory) != 1 || issue.StatusHistory[0].From != "To Do" || issue.StatusHistory[0].To != "In Progress" {
+		t.Errorf("StatusHistory = %+v", issue.StatusHistory)
+	}
+}
+
+func TestApplyChangelog_customDueField(t *testing.T) {
+	issue := &IssueData{Key: "E-2", Due: "2025-05-01"}
+	histories := []map[string]any{
+		history("2025-01-01T10:00:00.000+0000", changeItem("Planned end", "customfield_10100", "", "", "2025-03-01", "2025-05-01")),
+		history("2025-01-02T10:00:00.000+0000", changeItem("duedate", "duedate", "2025-01-01", "2025-09-01", "", "")),
+	}
+	applyChangelog(issue, histories, "customfield_10100", "Planned end")
+	if len(issue.DueHistory) != 1 || dueSlipCount(issue) != 1 || issue.OriginalDue != "2025-03-01" {
+		t.Errorf("custom due field: history=%+v slips=%d original=%q", issue.DueHistory, dueSlipCount(issue), issue.OriginalDue)
+	}
+
+	unchanged := &IssueData{Key: "E-3", Due: "2025-05-01"}
+	applyChangelog(unchanged, nil, "", "")
+	if unchanged.DueSlips == nil || *unchanged.DueSlips != 0 || unchanged.OriginalDue != "2025-05-01" {
+		t.Errorf("no history: slips=%v original=%q, want a loaded count of 0", unchanged.DueSlips, unchanged.OriginalDue)
+	}
+	// a loaded count of 0 stays in JSON; without a changelog the field is absent
+	if data, _ := json.Marshal(unchanged); !strings.Contains(string(data), `"due_slips":0`) {
+		t.Errorf("JSON should keep a loaded 0: %s", data)
+	}
+	if data, _ := json.Marshal(&IssueData{Key: "E-4"}); strings.Contains(string(data), "due_slips") {
+		t.Errorf("JSON without a changelog should omit due_slips: %s", data)
+	}
+}
+
+func TestChangelogDateString(t *testing.T) {
+	for in, want := range map[string]string{
+		"2025-03-01":            "2025-03-01",
+		"2025-03-01 00:00:00.0": "2025-03-01",
+		"1/Mar/25":              "2025-03-01",
+		"2025-03-01T08:00:00Z":  "2025-03-01",
+		"soon":                  "soon",
+	} {
+		if got := changelogDateString(in); got != want {
+			t.Errorf("changelogDateString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoadChangelogs_cloudFetchesLongChangelogs(t *testing.T) {
+	var changelogCalls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/search/jql":
+			if r.URL.Query().Get("expand") != "changelog" {
+				t.Errorf("expand = %q, want changelog", r.URL.Query().Get("expand"))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"isLast": true, "issues": []any{
+				map[string]any{"key": "A-1", "changelog": map[string]any{"total": 1, "histories": []any{
+					history("2025-01-01T00:00:00.000+0000", changeItem("duedate", "duedate", "2025-01-10", "2025-02-10", "", "")),
+				}}},
+				map[string]any{"key": "C-1", "changelog": map[string]any{"total": 2, "histories": []any{
+					history("2025-01-05T00:00:00.000+0000", changeItem("duedate", "duedate", "2025-01-20", "2025-03-01", "", "")),
+				}}},
+			}})
+		case "/rest/api/3/issue/C-1/changelog":
+			changelogCalls++
+			json.NewEncoder(w).Encode(map[string]any{"isLast": true, "total": 2, "values": []any{
+				history("2025-01-01T00:00:00.000+0000", changeItem("duedate", "duedate", "", "2025-01-20", "", "")),
+				history("2025-01-05T00:00:00.000+0000", changeItem("duedate", "duedate", "2025-01-20", "2025-03-01", "", "")),
+			}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	c.IsCloud = true
+	c.APIVersion = "3"
+	child := &IssueData{Key: "C-1"}
+	parent := &IssueData{Key: "A-1", Children: []*IssueData{child}}
+	if err := c.loadChangelogs(context.Background(), []*IssueData{parent}); err != nil {
+		t.Fatalf("loadChangelogs: %v", err)
+	}
+	if dueSlipCount(parent) != 1 || parent.OriginalDue != "2025-01-10" {
+		t.Errorf("parent: slips=%d original=%q", dueSlipCount(parent), parent.OriginalDue)
+	}
+	if changelogCalls != 1 || len(child.DueHistory) != 2 || child.OriginalDue != "2025-01-20" {
+		t.Errorf("child should be re-read in full: calls=%d history=%+v original=%q", changelogCalls, child.DueHistory, child.OriginalDue)
+	}
+}
+
+func TestDueSlips_reportedAndUsableByTrendingRules(t *testing.T) {
+	issue := &IssueData{Key: "E-1", Status: "in progress", Due: "2025-06-01", DueSlips: intPtr(3), OriginalDue: "2025-02-01"}
+
+	md := RenderMarkdownReport([]*IssueData{issue}, &ReportConfig{Title: "T"})
+	if !strings.Contains(md, "(slipped 3 times, originally 2025-02-01)") {
+		t.Errorf("markdown due cell should show slips: %s", md)
+	}
+	csv := RenderCSVReport([]*IssueData{issue}, &ReportConfig{LoadChangelog: true})
+	lines := strings.Split(csv, "\n")
+	if !strings.HasSuffix(lines[0], "due_slips"+csvSep+"original_due") || !strings.HasSuffix(lines[1], csvSep+"3"+csvSep+"2025-02-01") {
+		t.Errorf("CSV should end with due_slips,original_due:\n%s", csv)
+	}
+	if csv := RenderCSVReport([]*IssueData{issue}, &ReportConfig{}); strings.Contains(csv, "due_slips") {
+		t.Errorf("CSV without --changelog should keep the original columns:\n%s", csv)
+	}
+
+	rules, _, err := loadTrendingRules(writeTrendingRules(t, `{"rules": [
+  {"name": "slipping", "when": {"due_slips": {"min": 3}}, "trending": "at risk",
+   "comment": "due date moved {{.Issue.DueSlips}} times (was {{.Issue.OriginalDue}})"}
+]}`))
+	if err != nil {
+		t.Fatalf("loadTrendingRules: %v", err)
+	}
+	if !usesDueSlips(rules) || usesDueSlips(defaultTrendingRules()) {
+		t.Error("usesDueSlips should detect due_slips conditions")
+	}
+	useTrendingRules(t, rules)
+	issue.Trending = ""
+	computeTrending(issue, false)
+	if issue.Trending != "at risk" || issue.TrendingComment != "due date moved 3 times (was 2025-02-01)" {
+		t.Errorf("slip rule: %q / %q", issue.Trending, issue.TrendingComment)
+	}
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
-	"slices"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,6 +26,19 @@ const (
 	defaultParentLinkFieldName = "Parent Link"
 )
 
+// defaultChildLinkType is the issue link (outward description) that marks a linked issue as a child.
+const defaultChildLinkType = "is parent of"
+
+// defaultHierarchyFieldNames are the custom fields whose value names an issue's parent.
+func defaultHierarchyFieldNames() []string {
+	return []string{defaultEpicLinkFieldName, defaultParentLinkFieldName}
+}
+
+// defaultChildLinkTypes are the issue link types followed for child discovery.
+func defaultChildLinkTypes() []string {
+	return []string{defaultChildLinkType}
+}
+
 // JiraClient is a simple Jira REST API client
 type JiraClient struct {
 	Server     string
@@ -33,6 +50,15 @@ type JiraClient struct {
 
 	// MaxConcurrent caps parallel REST calls (issue fetch, child JQL, comment batches). If < 1, defaultJiraConcurrency is used.
 	MaxConcurrent int
+	// MaxResults caps issues returned per JQL search (parent query and each child query). If < 1, searches are unlimited.
+	MaxResults int
+	// MaxRetries caps retries per request after 429, 5xx, or network errors. 0 uses defaultMaxRetries; < 0 disables retries.
+	MaxRetries int
+	// RetryBudget caps total retries across the run (all goroutines). If < 1, defaultRetryBudget is used.
+	RetryBudget int
+	retriesUsed atomic.Int64
+	// truncated is set when any search stopped at MaxResults with more issues on the server.
+	truncated atomic.Bool
 
 	muCustomFields     sync.Mutex
 	customFieldsLoaded bool
@@ -42,9 +68,14 @@ type JiraClient struct {
 	dueDateFieldName        string // custom field display name; empty => use native duedate only
 	trendingStatusFieldName string
 	customFieldNameToID     map[string]string // display name -> API id
+
+	// Child discovery (see childrenJQL). nil slices mean the defaults.
+	childLinkTypes      []string
+	hierarchyFieldNames []string
+	noChildIssuesOf     bool
 }
 
-// Status categories mapped to emojis
+// trendingEmojis maps trending values to emojis (defaults; see activeStatusMap)
 var trendingEmojis = map[string]string{
 	"done":        "🟣",
 	"on track":    "🟢",
@@ -53,7 +84,7 @@ var trendingEmojis = map[string]string{
 	"not started": "⚪",
 }
 
-// statusEmojis maps status to action/semantic icons (play, check, stop, etc.)
+// statusEmojis maps status to action/semantic icons (play, check, stop, etc.) (defaults; see activeStatusMap)
 var statusEmojis = map[string]string{
 	"closed":         "❎",
 	"resolved":       "🎉",
@@ -65,7 +96,7 @@ var statusEmojis = map[string]string{
 	"unknown":        "❓",
 }
 
-// statusOrder defines the sort priority for statuses
+// statusOrder defines the built-in statuses and their default sort priority (see activeStatusMap)
 var statusOrder = []string{
 	"closed",
 	"resolved",
@@ -77,6 +108,26 @@ var statusOrder = []string{
 	"unknown",
 }
 
+// statusCategoryFallback maps Jira's status category key to the closest built-in status, so
+// workflow-specific statuses ("In Review", "QA", "Won't Do") still sort and trend sensibly.
+var statusCategoryFallback = map[string]string{
+	"new":           "new",
+	"indeterminate": "in progress",
+	"done":          "resolved",
+}
+
+// normalizeStatus returns the canonical status for a Jira status name from activeStatusMap, falling
+// back to its status category key (new/indeterminate/done), or "unknown" when neither is recognized.
+func normalizeStatus(name, categoryKey string) string {
+	if info, ok := activeStatusMap.lookup(name); ok {
+		return info.Status
+	}
+	if s, ok := statusCategoryFallback[strings.ToLower(strings.TrimSpace(categoryKey))]; ok {
+		return s
+	}
+	return "unknown"
+}
+
 // IssueData represents extracted issue data
 type IssueComment struct {
 	Url     string `json:"url"`
@@ -86,7 +137,8 @@ type IssueData struct {
 	Key             string       `json:"key"`
 	URL             string       `json:"url"`
 	Summary         string       `json:"summary"`
-	Status          string       `json:"status"`
+	Status          string       `json:"status"`                // canonical status (activeStatusMap); drives sorting and trending
+	StatusName      string       `json:"status_name,omitempty"` // workflow status name as shown in Jira
 	StatusEmoji     string       `json:"status_emoji"`
 	Assignee        string       `json:"assignee"`
 	Priority        string       `json:"priority"`
@@ -96,25 +148,72 @@ type IssueData struct {
 	Trending        string       `json:"trending"`
 	TrendingEmoji   string       `json:"Emoji"` // cache compat: historical key name
 	TrendingComment string       `json:"trending_comment"`
+	TrendingField   string       `json:"trending_field,omitempty"` // raw JIRA_TRENDING_STATUS_FIELD value, when resolved
 	Comment         IssueComment `json:"comment"`
-	Type            string       `json:"type"` // initiative, epic, story, subtask, …
+	Type            string       `json:"type"`                 // initiative, epic, story, subtask, …
+	ParentKey       string       `json:"parent_key,omitempty"` // native parent (fields.parent), else the parent it was loaded under
 	Children        []*IssueData `json:"children"`
+	// Extra holds the values of the --field names and of custom fields selected by --columns or --sort,
+	// by display name (see ReportConfig.extraFieldNames).
+	Extra map[string]string `json:"extra,omitempty"`
+
+	// Filled by loadChangelogs (--changelog); see changelog.go.
+	DueHistory    []FieldChange `json:"due_history,omitempty"`
+	StatusHistory []FieldChange `json:"status_history,omitempty"`
+	DueSlips      *int          `json:"due_slips,omitempty"`    // times the due date moved later; nil without a changelog
+	OriginalDue   string        `json:"original_due,omitempty"` // first due date ever set
+
+	// Filled from issuelinks when LoadIssueLinks (--dot); see blockingLinks.
+	Blocks    []LinkedIssue `json:"blocks,omitempty"`
+	BlockedBy []LinkedIssue `json:"blocked_by,omitempty"`
+}
+
+// LinkedIssue is the other end of an issue link, which may not be part of the report.
+type LinkedIssue struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// blockingLinks splits an issue's issuelinks field into the issues it blocks and the issues blocking it.
+// Jira's default "Blocks" link type reads "blocks" outward and "is blocked by" inward.
+func blockingLinks(fields map[string]any) (blocks, blockedBy []LinkedIssue) {
+	for _, link := range getMapList(fields, "issuelinks") {
+		linkType := getMap(link, "type")
+		if !strings.EqualFold(getString(linkType, "name", ""), "Blocks") && !strings.EqualFold(getString(linkType, "outward", ""), "blocks") {
+			continue
+		}
+		if other := getMap(link, "outwardIssue"); other != nil {
+			blocks = append(blocks, LinkedIssue{Key: getString(other, "key", ""), Summary: getString(getMap(other, "fields"), "summary", "")})
+		}
+		if other := getMap(link, "inwardIssue"); other != nil {
+			blockedBy = append(blockedBy, LinkedIssue{Key: getString(other, "key", ""), Summary: getString(getMap(other, "fields"), "summary", "")})
+		}
+	}
+	return blocks, blockedBy
+}
+
+// jiraAPIVersion returns the REST API version used for server: "3" on Jira Cloud (*.atlassian.net),
+// which renders ADF, else "2" (Server/Data Center, wiki markup).
+func jiraAPIVersion(server string) string {
+	if strings.Contains(strings.ToLower(server), ".atlassian.net") {
+		return "3"
+	}
+	return "2"
 }
 
 // NewJiraClient creates a new Jira client
-func NewJiraClient(server, apiToken, email string) (*JiraClient, error) {
+func NewJiraClient(ctx context.Context, server, apiToken, email string) (*JiraClient, error) {
 	if server == "" || apiToken == "" {
 		return nil, fmt.Errorf("failed to connect to Jira. Check your credentials and server URL.\nFor Jira Server/Data Center, ensure you're using a valid Personal Access Token (PAT)")
 	}
 	server = strings.TrimRight(server, "/")
 	isCloud := strings.Contains(strings.ToLower(server), ".atlassian.net")
 
-	apiVersion := "2"
+	apiVersion := jiraAPIVersion(server)
 	if isCloud {
 		if email == "" {
 			return nil, fmt.Errorf("JIRA_EMAIL is required for Jira Cloud authentication")
 		}
-		apiVersion = "3"
 		logDebug("Using Jira Cloud authentication (API v%s)", apiVersion)
 	} else {
 		logDebug("Using Jira on-prem authentication (API v%s)", apiVersion)
@@ -129,7 +228,7 @@ func NewJiraClient(server, apiToken, email string) (*JiraClient, error) {
 		HTTPClient: &http.Client{Timeout: 30 * time.Second},
 	}
 
-	if !client.TestConnection() {
+	if !client.TestConnection(ctx) {
 		return nil, fmt.Errorf("failed to connect to Jira. Check your credentials and server URL.\nFor Jira Server/Data Center, ensure you're using a valid Personal Access Token (PAT)")
 	}
 
@@ -156,10 +255,17 @@ func (c *JiraClient) prepareFieldResolution(cfg *ReportConfig) {
 		}
 		c.customFieldNameToID[name] = ""
 	}
+	c.childLinkTypes = cfg.ChildLinkTypes
+	c.hierarchyFieldNames = cfg.HierarchyFieldNames
+	c.noChildIssuesOf = cfg.NoChildIssuesOf
 	add(c.dueDateFieldName)
 	add(c.trendingStatusFieldName)
-	add(defaultEpicLinkFieldName)
-	add(defaultParentLinkFieldName)
+	for _, name := range cfg.ExtraFields {
+		add(name)
+	}
+	for _, name := range c.hierarchyFields() {
+		add(name)
+	}
 	c.customFieldsLoaded = false
 }
 
@@ -171,17 +277,24 @@ func (c *JiraClient) concurrencyCap() int {
 }
 
 // ensureCustomFieldsLoaded resolves custom field IDs once; safe for concurrent FetchIssuesFromQuery/searchIssues.
-func (c *JiraClient) ensureCustomFieldsLoaded() {
+func (c *JiraClient) ensureCustomFieldsLoaded(ctx context.Context) {
 	c.muCustomFields.Lock()
 	defer c.muCustomFields.Unlock()
 	if c.customFieldsLoaded {
 		return
 	}
 	if len(c.customFieldNameToID) > 0 {
-		if err := c.loadCustomFields(c.customFieldNameToID); err != nil {
+		if err := c.loadCustomFields(ctx, c.customFieldNameToID); err != nil {
 			logWarning("Could not load custom fields: %v", err)
 		}
 	}
+	if c.fieldCfg != nil {
+		for _, name := range c.fieldCfg.ExtraFields {
+			if c.customFieldNameToID[strings.TrimSpace(name)] == "" {
+				logWarning("Field %q not found in Jira; its column will be empty", name)
+			}
+		}
+	}
 	if c.fieldCfg != nil {
 		out := make(map[string]string, len(c.customFieldNameToID))
 		for k, v := range c.customFieldNameToID {
@@ -192,87 +305,19 @@ func (c *JiraClient) ensureCustomFieldsLoaded() {
 	c.customFieldsLoaded = true
 }
 
-// computeTrending computes the trending status for an issue and its children
-func computeTrending(issue *IssueData, includeChildren bool) {
-	if issue.Trending != "" {
-		return // already computed
-	}
-	if issue.Status == "unknown" {
-		issue.Trending = "unknown"
-		issue.TrendingEmoji = "❓"
-		return
-	}
-
-	// approximate trending based on status
-	trending := "not started" // default to not started
-
-	switch issue.Status {
-	case "closed", "resolved":
-		trending = "done"
-		issue.TrendingComment = "🎉"
-	case "blocked":
-		trending = "off track"
-	case "not started", "new", "vetting", "ready for work":
-		trending = "not started"
-		if isDueWithinDays(issue.Due, 30) {
-			trending = "at risk"
-			issue.TrendingComment = fmt.Sprintf("due within %d days but not started.", 30)
-		}
-	case "in progress":
-		trending = "on track"
-	default:
-		trending = "" // inconclusive
-	}
-
-	// past target end -> off track (unless already done)
-	if trending != "done" && isStale(issue.Due) {
-		trending = "off track"
-	}
-
-	// consider the children
-	if includeChildren && len(issue.Children) > 0 {
-		if trending != "done" && trending != "blocked" && trending != "at risk" && trending != "off track" {
-			for _, child := range issue.Children {
-				// what's their status? (recursive)
-				computeTrending(child, includeChildren)
+// greenhopperSprintRe matches the name in Jira Server's serialized sprint values
+// ("com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=1,rapidViewId=2,state=ACTIVE,name=Sprint 7,...]").
+var greenhopperSprintRe = regexp.MustCompile(`\[.*\bname=([^,\]]*)`)
 
-				// if children are in any of the following statuses, set trending to that status
-				for _, t := range []string{"blocked", "off track", "at risk"} {
-					if child.Trending == t {
-						trending = t
-						issue.TrendingComment = fmt.Sprintf("child %s is '%s'", child.Key, t)
-						break
-					}
-				}
-			}
-
-			// if all children are done, set trending to done
-			if trending != "done" {
-				allDone := true
-				for _, child := range issue.Children {
-					computeTrending(child, includeChildren)
-					if child.Trending != "done" {
-						allDone = false
-						break
-					}
-				}
-				if allDone {
-					trending = "done"
-					issue.TrendingComment = "All children are done. What's left?"
-				}
-			}
-		}
+// greenhopperSprintName returns the sprint name from a serialized Jira Server sprint, or "".
+func greenhopperSprintName(s string) string {
+	if !strings.Contains(s, "greenhopper") {
+		return ""
 	}
-
-	// get emoji
-	trendingEmoji := "❓"
-	if value, ok := trendingEmojis[trending]; ok {
-		trendingEmoji = value
+	if m := greenhopperSprintRe.FindStringSubmatch(s); m != nil {
+		return strings.TrimSpace(m[1])
 	}
-
-	// save values
-	issue.Trending = trending
-	issue.TrendingEmoji = trendingEmoji
+	return ""
 }
 
 // jiraFieldStringValue returns a display string for a Jira issue fields value (string, option object, multi-select, etc.).
@@ -294,7 +339,10 @@ func jiraFieldStringValue(fields map[string]any, fieldID string) string {
 		if s := getString(x, "name", ""); s != "" {
 			return strings.TrimSpace(s)
 		}
-		return strings.TrimSpace(getString(x, "displayName", ""))
+		if s := getString(x, "displayName", ""); s != "" {
+			return strings.TrimSpace(s)
+		}
+		return strings.TrimSpace(getString(x, "title", ""))
 	case []any:
 		var parts []string
 		for _, el := range x {
@@ -308,7 +356,9 @@ func jiraFieldStringValue(fields map[string]any, fieldID string) string {
 					parts = append(parts, p)
 				}
 			case string:
-				if strings.TrimSpace(e) != "" {
+				if name := greenhopperSprintName(e); name != "" {
+					parts = append(parts, name)
+				} else if strings.TrimSpace(e) != "" {
 					parts = append(parts, strings.TrimSpace(e))
 				}
 			}
@@ -320,7 +370,8 @@ func jiraFieldStringValue(fields map[string]any, fieldID string) string {
 }
 
 // extractIssueData extracts relevant data from a Jira issue API response.
-// Parent/child relationships are represented only via IssueData.Children after loadChildren.
+// Parent/child relationships are represented via IssueData.Children after loadChildren; ParentKey
+// records the native parent (sub-task parent, or any level of Cloud's unified hierarchy) when present.
 func (c *JiraClient) extractIssueData(issue map[string]any) *IssueData {
 	fields := getMap(issue, "fields")
 	issueKey := getString(issue, "key", "")
@@ -330,14 +381,11 @@ func (c *JiraClient) extractIssueData(issue map[string]any) *IssueData {
 	typeRaw := getString(typeObj, "name", "Unknown")
 	typeNormalized := strings.ToLower(strings.TrimSpace(typeRaw))
 
-	// Get status
+	// Get status (the status field carries statusCategory, used when the name is not in the status map)
 	statusObj := getMap(fields, "status")
-	statusRaw := getString(statusObj, "name", "unknown")
-	statusNormalized := strings.ToLower(strings.TrimSpace(statusRaw))
-	if !slices.Contains(statusOrder, statusNormalized) {
-		statusNormalized = "unknown" // only known statuses are in statusOrder
-	}
-	statusEmoji := statusEmojis[statusNormalized]
+	statusName := strings.TrimSpace(getString(statusObj, "name", ""))
+	statusNormalized := normalizeStatus(statusName, getString(getMap(statusObj, "statusCategory"), "key", ""))
+	statusEmoji := activeStatusMap.forIssue(&IssueData{Status: statusNormalized, StatusName: statusName}).Emoji
 
 	// Get assignee
 	assigneeObj := getMap(fields, "assignee")
@@ -364,16 +412,15 @@ func (c *JiraClient) extractIssueData(issue map[string]any) *IssueData {
 
 	trendingStr := ""
 	trendingEmo := ""
+	trendingField := ""
 	if c != nil && c.trendingStatusFieldName != "" && c.customFieldNameToID != nil {
 		if id := c.customFieldNameToID[c.trendingStatusFieldName]; id != "" {
 			raw := jiraFieldStringValue(fields, id)
-			if raw != "" {
+			trendingField = raw
+			// With --write-trending the field is the target, so trending is computed rather than read from it.
+			if raw != "" && (c.fieldCfg == nil || !c.fieldCfg.WriteTrending) {
 				trendingStr = strings.ToLower(strings.TrimSpace(raw))
-				if e, ok := trendingEmojis[trendingStr]; ok {
-					trendingEmo = e
-				} else {
-					trendingEmo = "❓"
-				}
+				trendingEmo = activeStatusMap.trendingEmoji(trendingStr)
 			}
 		}
 	}
@@ -381,6 +428,29 @@ func (c *JiraClient) extractIssueData(issue map[string]any) *IssueData {
 	// Get summary
 	summary := getString(fields, "summary", "")
 
+	// Get native parent (team-managed projects and Cloud's unified hierarchy replace Epic Link with it)
+	parentKey := getString(getMap(fields, "parent"), "key", "")
+
+	// Keep the values of the --field names and the custom fields --columns and --sort select
+	var extra map[string]string
+	if c != nil && c.fieldCfg != nil {
+		for _, name := range c.fieldCfg.extraFieldNames() {
+			id := c.customFieldNameToID[name]
+			if id == "" {
+				continue
+			}
+			if v := jiraFieldStringValue(fields, id); v != "" {
+				if extra == nil {
+					extra = make(map[string]string)
+				}
+				extra[name] = v
+			}
+		}
+	}
+
+	// Get blocking links (only requested with LoadIssueLinks)
+	blocks, blockedBy := blockingLinks(fields)
+
 	serverURL := ""
 	if c != nil {
 		serverURL = c.Server
@@ -393,6 +463,7 @@ func (c *JiraClient) extractIssueData(issue map[string]any) *IssueData {
 		URL:           issueURL,
 		Summary:       summary,
 		Status:        statusNormalized,
+		StatusName:    statusName,
 		Assignee:      assignee,
 		Priority:      priority,
 		Created:       created,
@@ -401,24 +472,29 @@ func (c *JiraClient) extractIssueData(issue map[string]any) *IssueData {
 		StatusEmoji:   statusEmoji,
 		Trending:      trendingStr,
 		TrendingEmoji: trendingEmo,
+		TrendingField: trendingField,
+		ParentKey:     parentKey,
+		Blocks:        blocks,
+		BlockedBy:     blockedBy,
+		Extra:         extra,
 	}
 }
 
 // FetchIssue fetches issue details from Jira
-func (client *JiraClient) FetchIssue(issueKey string) (*IssueData, error) {
-	issues, err := client.FetchIssuesByKeys([]string{issueKey})
+func (client *JiraClient) FetchIssue(ctx context.Context, issueKey string) (*IssueData, error) {
+	issues, err := client.FetchIssuesByKeys(ctx, []string{issueKey})
 	if err != nil {
 		return nil, err
 	}
 	return issues[0], nil
 }
 
-func (client *JiraClient) FetchIssuesFromQuery(jqlQuery string) ([]*IssueData, error) {
+func (client *JiraClient) FetchIssuesFromQuery(ctx context.Context, jqlQuery string) ([]*IssueData, error) {
 	logInfo("Executing JQL query: %s", jqlQuery)
 
 	issues := []*IssueData{} // we don't know how many there will be
 
-	jsonBlobs, err := client.searchIssues(jqlQuery, 1000)
+	jsonBlobs, err := client.searchIssues(ctx, jqlQuery, client.MaxResults)
 	if err != nil {
 		logError("JQL query failed: %v", err)
 		return nil, err
@@ -434,7 +510,7 @@ func (client *JiraClient) FetchIssuesFromQuery(jqlQuery string) ([]*IssueData, e
 }
 
 // FetchIssuesByKeys loads issues in parallel (bounded by MaxConcurrent), preserving input key order; failed keys are skipped.
-func (client *JiraClient) FetchIssuesByKeys(issueKeys []string) ([]*IssueData, error) {
+func (client *JiraClient) FetchIssuesByKeys(ctx context.Context, issueKeys []string) ([]*IssueData, error) {
 	if len(issueKeys) == 0 {
 		return nil, nil
 	}
@@ -450,7 +526,7 @@ func (client *JiraClient) FetchIssuesByKeys(issueKeys []string) ([]*IssueData, e
 		jql := fmt.Sprintf("key in (%s)", strings.Join(batch, ","))
 
 		// fetch the issues
-		result, err := client.FetchIssuesFromQuery(jql)
+		result, err := client.FetchIssuesFromQuery(ctx, jql)
 		if err != nil {
 			logError("Failed to fetch issues: %v", err)
 			return nil, err
@@ -460,46 +536,155 @@ func (client *JiraClient) FetchIssuesByKeys(issueKeys []string) ([]*IssueData, e
 	return issues, nil
 }
 
-// childrenJQL builds JQL to find child issues: sub-tasks, parent links, epic links, and linked children.
-// Epic Link and Parent Link clauses are included only when those fields resolve on the Jira instance.
+// childLinks returns the configured child link types, or defaultChildLinkTypes when unset.
+func (c *JiraClient) childLinks() []string {
+	if c.childLinkTypes == nil {
+		return defaultChildLinkTypes()
+	}
+	return c.childLinkTypes
+}
+
+// hierarchyFields returns the configured hierarchy field names, or defaultHierarchyFieldNames when unset.
+func (c *JiraClient) hierarchyFields() []string {
+	if c.hierarchyFieldNames == nil {
+		return defaultHierarchyFieldNames()
+	}
+	return c.hierarchyFieldNames
+}
+
+// childrenJQL builds JQL to find child issues: the native parent field (sub-tasks, and every level of
+// Cloud's unified hierarchy), childIssuesOf, linked children for each configured link type, and each
+// configured hierarchy field (e.g. Epic Link, Parent Link).
+// Hierarchy field clauses are included only when those fields resolve on the Jira instance. The parent
+// clause is always present, so the search is never unbounded.
 func (c *JiraClient) childrenJQL(parentKey string) string {
-	c.ensureCustomFieldsLoaded()
-	clauses := []string{
-		fmt.Sprintf(`issue in childIssuesOf(%s)`, parentKey),
-		fmt.Sprintf(`issue in linkedIssues(%s, "is parent of")`, parentKey),
+	clauses := []string{fmt.Sprintf(`parent = %s`, parentKey)}
+	if !c.noChildIssuesOf {
+		clauses = append(clauses, fmt.Sprintf(`issue in childIssuesOf(%s)`, parentKey))
 	}
-	for _, fieldName := range []string{defaultEpicLinkFieldName, defaultParentLinkFieldName} {
+	for _, linkType := range c.childLinks() {
+		clauses = append(clauses, fmt.Sprintf(`issue in linkedIssues(%s, %s)`, parentKey, jqlQuote(linkType)))
+	}
+	for _, fieldName := range c.hierarchyFields() {
 		if id := strings.TrimSpace(c.customFieldNameToID[fieldName]); id != "" {
-			clauses = append(clauses, fmt.Sprintf(`"%s" = %s`, fieldName, parentKey))
+			clauses = append(clauses, fmt.Sprintf(`%s = %s`, jqlQuote(fieldName), parentKey))
 		}
 	}
 	return strings.Join(clauses, " OR ")
 }
 
-func (client *JiraClient) loadChildren(parents []*IssueData) {
+// jqlQuote returns s as a double-quoted JQL string literal.
+func jqlQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// loadChildren walks the hierarchy breadth-first from parents, up to depth levels (depth < 1 loads one level),
+// filling Children. Each level fans out with MaxConcurrent parallel searches. An issue reached from several
+// parents is fetched once and shared; a link that would make an issue its own descendant (e.g. an
+// "is parent of" cycle) is dropped, so computeTrending can recurse safely. Per-parent failures are logged
+// and leave that parent without children; cancellation of ctx stops the walk and is returned.
+func (client *JiraClient) loadChildren(ctx context.Context, parents []*IssueData, depth int) error {
+	client.ensureCustomFieldsLoaded(ctx)
+	depth = max(depth, 1)
+	logInfo("Child JQL (depth %d): %s", depth, client.childrenJQL("<KEY>"))
+
+	nodes := make(map[string]*IssueData) // key -> the single IssueData used everywhere in the tree
+	var frontier []*IssueData
+	for _, p := range parents {
+		if p == nil {
+			continue
+		}
+		if _, seen := nodes[p.Key]; !seen {
+			nodes[p.Key] = p
+			frontier = append(frontier, p)
+		}
+	}
+
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		found := client.fetchChildren(ctx, frontier)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var next []*IssueData
+		for i, p := range frontier {
+			if found[i] == nil {
+				continue
+			}
+			children := make([]*IssueData, 0, len(found[i]))
+			for _, child := range found[i] {
+				if existing, seen := nodes[child.Key]; seen {
+					if existing == p || reachesIssue(existing, p) {
+						logDebug("Skipping %s as child of %s: would create a cycle", child.Key, p.Key)
+						continue
+					}
+					children = append(children, existing)
+					continue
+				}
+				if child.ParentKey == "" {
+					child.ParentKey = p.Key
+				}
+				nodes[child.Key] = child
+				next = append(next, child)
+				children = append(children, child)
+			}
+			p.Children = children
+		}
+		logInfo("Loaded level %d of children: %d new issues", level, len(next))
+		frontier = next
+	}
+	return ctx.Err()
+}
+
+// reachesIssue reports whether target is from or one of its descendants via Children.
+func reachesIssue(from, target *IssueData) bool {
+	visited := make(map[*IssueData]struct{})
+	stack := []*IssueData{from}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == target {
+			return true
+		}
+		if _, ok := visited[n]; ok {
+			continue
+		}
+		visited[n] = struct{}{}
+		stack = append(stack, n.Children...)
+	}
+	return false
+}
+
+// fetchChildren runs the child JQL for each parent in parallel (bounded by MaxConcurrent) and returns
+// de-duplicated children aligned with parents. A nil entry means that parent's search failed.
+func (client *JiraClient) fetchChildren(ctx context.Context, parents []*IssueData) [][]*IssueData {
+	found := make([][]*IssueData, len(parents))
 	lim := client.concurrencyCap()
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, lim)
-	for _, parent := range parents {
-		if parent == nil {
-			continue
-		}
+	for i, parent := range parents {
 		wg.Add(1)
-		go func(p *IssueData) {
+		go func(i int, p *IssueData) {
 			defer wg.Done()
-			sem <- struct{}{}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-sem }()
 
 			jql := client.childrenJQL(p.Key)
 			logInfo("Loading children for %s: %s", p.Key, jql)
-			jsonBlobs, err := client.searchIssues(jql, 1000)
+			jsonBlobs, err := client.searchIssues(ctx, jql, client.MaxResults)
 			if err != nil {
-				logError("Failed to load children for %s: %v", p.Key, err)
-				p.Children = nil
+				if ctx.Err() == nil {
+					logError("Failed to load children for %s: %v", p.Key, err)
+				}
 				return
 			}
 			seen := make(map[string]struct{}, len(jsonBlobs))
-			var children []*IssueData
+			children := []*IssueData{}
 			for _, blob := range jsonBlobs {
 				child := client.extractIssueData(blob)
 				if child == nil || child.Key == "" {
@@ -511,20 +696,22 @@ func (client *JiraClient) loadChildren(parents []*IssueData) {
 				seen[child.Key] = struct{}{}
 				children = append(children, child)
 			}
-			p.Children = children
+			found[i] = children
 			logInfo("  Found %d children for %s", len(children), p.Key)
-		}(parent)
+		}(i, parent)
 	}
 	wg.Wait()
+	return found
 }
 
 // resolves custom field names to IDs
-func (c *JiraClient) loadCustomFields(fieldNames map[string]string) error {
-	fields, err := c.getJsonList("field", nil)
+func (c *JiraClient) loadCustomFields(ctx context.Context, fieldNames map[string]string) error {
+	fields, err := c.getJsonList(ctx, "field", nil)
 	if err != nil {
 		return err
 	}
 
+	// Match display names first ("Story Points"), then field IDs ("labels", "customfield_10016").
 	for name := range fieldNames {
 		for _, f := range fields {
 			if getString(f, "name", "") == name {
@@ -532,17 +719,29 @@ func (c *JiraClient) loadCustomFields(fieldNames map[string]string) error {
 				break
 			}
 		}
+		if fieldNames[name] != "" {
+			continue
+		}
+		for _, f := range fields {
+			if id := getString(f, "id", ""); strings.EqualFold(id, name) {
+				fieldNames[name] = id
+				break
+			}
+		}
 	}
 
 	return nil
 }
 
 // searchIssues searches for issues using JQL with pagination
-func (c *JiraClient) searchIssues(jql string, maxResults int) ([]map[string]any, error) {
+func (c *JiraClient) searchIssues(ctx context.Context, jql string, maxResults int) ([]map[string]any, error) {
 	var b strings.Builder
-	b.WriteString("summary,status,issuetype,assignee,priority,created,updated,duedate")
+	b.WriteString("summary,status,issuetype,assignee,priority,created,updated,duedate,parent")
+	if c.fieldCfg != nil && c.fieldCfg.LoadIssueLinks {
+		b.WriteString(",issuelinks")
+	}
 
-	c.ensureCustomFieldsLoaded()
+	c.ensureCustomFieldsLoaded(ctx)
 
 	for _, id := range c.customFieldNameToID {
 		if id != "" {
@@ -550,9 +749,46 @@ func (c *JiraClient) searchIssues(jql string, maxResults int) ([]map[string]any,
 			b.WriteString(id)
 		}
 	}
-	fields := b.String()
 
+	allIssues, truncated, err := c.searchPages(ctx, jql, b.String(), maxResults)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		c.truncated.Store(true)
+		logWarning("Results truncated at %d issues for JQL %q (raise --max-results, or use 0 for unlimited)", len(allIssues), jql)
+	}
+	logInfo("Fetched %d issues total", len(allIssues))
+	return allIssues, nil
+}
+
+// useEnhancedSearch reports whether searches go through /search/jql (nextPageToken pagination).
+// Atlassian is retiring GET /rest/api/3/search on Cloud; Server/Data Center (v2) only has /search.
+func (c *JiraClient) useEnhancedSearch() bool {
+	return c.IsCloud && c.APIVersion == "3"
+}
+
+// searchPages returns up to maxResults raw issues for jql (maxResults < 1 means unlimited), paging with
+// the style this server supports. truncated reports that the server had more matches than were returned.
+func (c *JiraClient) searchPages(ctx context.Context, jql, fields string, maxResults int) (issues []map[string]any, truncated bool, err error) {
+	return c.searchPagesExpand(ctx, jql, fields, "", maxResults)
+}
+
+// searchPagesExpand is searchPages with an expand parameter (e.g. "changelog"); expand "" sends none.
+func (c *JiraClient) searchPagesExpand(ctx context.Context, jql, fields, expand string, maxResults int) (issues []map[string]any, truncated bool, err error) {
+	if maxResults < 1 {
+		maxResults = math.MaxInt
+	}
+	if c.useEnhancedSearch() {
+		return c.searchByToken(ctx, jql, fields, expand, maxResults)
+	}
+	return c.searchByOffset(ctx, jql, fields, expand, maxResults)
+}
+
+// searchByOffset pages through GET /search with startAt/maxResults until total is reached.
+func (c *JiraClient) searchByOffset(ctx context.Context, jql, fields, expand string, maxResults int) ([]map[string]any, bool, error) {
 	var allIssues []map[string]any
+	truncated := false
 	startAt := 0
 	pageSize := min(defaultPageSize, maxResults)
 
@@ -563,11 +799,14 @@ func (c *JiraClient) searchIssues(jql string, maxResults int) ([]map[string]any,
 			"startAt":    fmt.Sprintf("%d", startAt),
 			"maxResults": fmt.Sprintf("%d", pageSize),
 		}
+		if expand != "" {
+			params["expand"] = expand
+		}
 
 		logDebug("Fetching issues: startAt=%d, maxResults=%d", startAt, pageSize)
-		response, err := c.getJson("search", params)
+		response, err := c.getJson(ctx, "search", params)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		issues := getMapList(response, "issues")
@@ -576,7 +815,11 @@ func (c *JiraClient) searchIssues(jql string, maxResults int) ([]map[string]any,
 		allIssues = append(allIssues, issues...)
 		logDebug("Fetched %d issues (total so far: %d, server total: %d)", len(issues), len(allIssues), total)
 
-		if len(allIssues) >= total || len(allIssues) >= maxResults {
+		if len(allIssues) >= total {
+			break
+		}
+		if len(allIssues) >= maxResults {
+			truncated = true
 			break
 		}
 
@@ -589,28 +832,92 @@ func (c *JiraClient) searchIssues(jql string, maxResults int) ([]map[string]any,
 		pageSize = min(defaultPageSize, remaining)
 	}
 
-	logInfo("Fetched %d issues total", len(allIssues))
 	if len(allIssues) > maxResults {
-		return allIssues[:maxResults], nil
+		return allIssues[:maxResults], true, nil
 	}
-	return allIssues, nil
+	return allIssues, truncated, nil
+}
+
+// searchByToken pages through GET /search/jql, following nextPageToken until isLast.
+// The enhanced search does not report a total, so the page loop stops on isLast, a missing token, or maxResults.
+func (c *JiraClient) searchByToken(ctx context.Context, jql, fields, expand string, maxResults int) ([]map[string]any, bool, error) {
+	var allIssues []map[string]any
+	truncated := false
+	nextPageToken := ""
+	pageSize := min(defaultPageSize, maxResults)
+
+	for page := 1; ; page++ {
+		params := map[string]string{
+			"jql":        jql,
+			"fields":     fields,
+			"maxResults": fmt.Sprintf("%d", pageSize),
+		}
+		if nextPageToken != "" {
+			params["nextPageToken"] = nextPageToken
+		}
+		if expand != "" {
+			params["expand"] = expand
+		}
+
+		logDebug("Fetching issues: page=%d, maxResults=%d", page, pageSize)
+		response, err := c.getJson(ctx, "search/jql", params)
+		if err != nil {
+			return nil, false, err
+		}
+
+		issues := getMapList(response, "issues")
+		allIssues = append(allIssues, issues...)
+		nextPageToken = getString(response, "nextPageToken", "")
+		logDebug("Fetched %d issues (total so far: %d)", len(issues), len(allIssues))
+
+		isLast, _ := response["isLast"].(bool)
+		if isLast || nextPageToken == "" || len(issues) == 0 {
+			break
+		}
+		if len(allIssues) >= maxResults {
+			truncated = true
+			break
+		}
+
+		pageSize = min(defaultPageSize, maxResults-len(allIssues))
+	}
+
+	if len(allIssues) > maxResults {
+		return allIssues[:maxResults], true, nil
+	}
+	return allIssues, truncated, nil
 }
 
 // GetComments fetches all comments for an issue
-func (c *JiraClient) GetComments(issueKey string) ([]map[string]any, error) {
-	resp, err := c.getJson(fmt.Sprintf("issue/%s/comment", issueKey), nil)
+func (c *JiraClient) GetComments(ctx context.Context, issueKey string) ([]map[string]any, error) {
+	resp, err := c.getJson(ctx, fmt.Sprintf("issue/%s/comment", issueKey), nil)
 	if err != nil {
 		return nil, err
 	}
 	return getMapList(resp, "comments"), nil
 }
 
+// PostComment adds a comment to issueKey. body is wiki markup (string) for API v2 or an ADF document for v3.
+func (c *JiraClient) PostComment(ctx context.Context, issueKey string, body any) (map[string]any, error) {
+	return c.postJson(ctx, fmt.Sprintf("issue/%s/comment", issueKey), map[string]any{"body": body})
+}
+
+// UpdateIssueFields sets fields (field ID -> value) on issueKey.
+func (c *JiraClient) UpdateIssueFields(ctx context.Context, issueKey string, fields map[string]any) error {
+	data, err := json.Marshal(map[string]any{"fields": fields})
+	if err != nil {
+		return err
+	}
+	_, err = c.doRequest(ctx, "PUT", fmt.Sprintf("issue/%s", issueKey), nil, data)
+	return err
+}
+
 const commentBatchSize = 50
 
 // GetMostRecentComments returns a map of issue key to the most recent comment (as a JSON blob).
 // Issues with no comments are omitted from the result.
 // Fetches in batches of at most commentBatchSize issue keys.
-func (c *JiraClient) loadComments(issues []*IssueData) error {
+func (c *JiraClient) loadComments(ctx context.Context, issues []*IssueData) error {
 	issueCount := len(issues)
 	result := make(map[string]map[string]any, issueCount)
 	lim := c.concurrencyCap()
@@ -629,9 +936,13 @@ func (c *JiraClient) loadComments(issues []*IssueData) error {
 		wg.Add(1)
 		go func(batch []*IssueData) {
 			defer wg.Done()
-			sem <- struct{}{}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-sem }()
-			batchResult, err := c.getMostRecentCommentsBulk(batch)
+			batchResult, err := c.getMostRecentCommentsBulk(ctx, batch)
 			if err != nil {
 				mu.Lock()
 				if firstErr == nil {
@@ -648,6 +959,9 @@ func (c *JiraClient) loadComments(issues []*IssueData) error {
 		}(batch)
 	}
 	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if firstErr != nil {
 		return firstErr
 	}
@@ -667,8 +981,8 @@ func (c *JiraClient) loadComments(issues []*IssueData) error {
 	return nil
 }
 
-// getMostRecentCommentsBulk fetches issues via search API with comment field (one request).
-func (c *JiraClient) getMostRecentCommentsBulk(issues []*IssueData) (map[string]map[string]any, error) {
+// getMostRecentCommentsBulk fetches issues via search API with comment field (one page of up to commentBatchSize).
+func (c *JiraClient) getMostRecentCommentsBulk(ctx context.Context, issues []*IssueData) (map[string]map[string]any, error) {
 	issueCount := len(issues)
 	result := make(map[string]map[string]any, issueCount)
 
@@ -679,18 +993,11 @@ func (c *JiraClient) getMostRecentCommentsBulk(issues []*IssueData) (map[string]
 	}
 	jql := "key in (" + strings.Join(quoted, ",") + ")"
 
-	params := map[string]string{
-		"jql":        jql,
-		"fields":     "comment",
-		"maxResults": fmt.Sprintf("%d", issueCount),
-	}
-
-	response, err := c.getJson("search", params)
+	responseIssues, _, err := c.searchPages(ctx, jql, "comment", issueCount)
 	if err != nil {
 		return nil, err
 	}
 
-	responseIssues := getMapList(response, "issues")
 	for _, issue := range responseIssues {
 		key := getString(issue, "key", "")
 		fields := getMap(issue, "fields")
@@ -721,8 +1028,8 @@ func findLatestComment(comments []map[string]any) map[string]any {
 }
 
 // TestConnection tests the connection to Jira
-func (c *JiraClient) TestConnection() bool {
-	_, err := c.getJson("myself", nil)
+func (c *JiraClient) TestConnection(ctx context.Context) bool {
+	_, err := c.getJson(ctx, "myself", nil)
 	if err != nil {
 		logError("Connection test failed: %v", err)
 		return false
@@ -730,62 +1037,129 @@ func (c *JiraClient) TestConnection() bool {
 	return true
 }
 
-// doRequest makes an authenticated request to the Jira API
-func (c *JiraClient) doRequest(method, endpoint string, params map[string]string) ([]byte, error) {
+// APIError is an HTTP error response (after retries) from a Jira or Confluence REST API.
+type APIError struct {
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %d", e.StatusCode)
+}
+
+// doRequest makes an authenticated request to the Jira API with an optional JSON body, retrying
+// rate-limited (429), transient 5xx, and network failures with backoff (see retry.go).
+func (c *JiraClient) doRequest(ctx context.Context, method, endpoint string, params map[string]string, body []byte) ([]byte, error) {
 	baseURL := fmt.Sprintf("%s/rest/api/%s/%s", c.Server, c.APIVersion, strings.TrimLeft(endpoint, "/"))
+	return c.sendRequest(ctx, method, withQueryParams(baseURL, params), body)
+}
 
-	// Add query params
-	if len(params) > 0 {
-		values := url.Values{}
-		for k, v := range params {
-			values.Set(k, v)
-		}
-		baseURL += "?" + values.Encode()
+// withQueryParams appends params to rawURL as an encoded query string.
+func withQueryParams(rawURL string, params map[string]string) string {
+	if len(params) == 0 {
+		return rawURL
+	}
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
 	}
+	return rawURL + "?" + values.Encode()
+}
 
-	logDebug("Request: %s %s", method, baseURL)
+// sendRequest sends an authenticated request to an absolute URL with an optional JSON body, using the
+// client's credentials and retry policy. Other Atlassian APIs (see confluence.go) share it. POST is not
+// idempotent, so it is only retried on 429, when the server has not acted on the request.
+func (c *JiraClient) sendRequest(ctx context.Context, method, baseURL string, body []byte) ([]byte, error) {
+	idempotent := method != http.MethodPost
+	for attempt := 0; ; attempt++ {
+		logDebug("Request: %s %s", method, baseURL)
+
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, baseURL, reqBody)
+		if err != nil {
+			return nil, err
+		}
 
-	req, err := http.NewRequest(method, baseURL, nil)
-	if err != nil {
-		return nil, err
-	}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
+		if c.IsCloud {
+			// Basic auth with email:token
+			auth := base64.StdEncoding.EncodeToString([]byte(c.Email + ":" + c.APIToken))
+			req.Header.Set("Authorization", "Basic "+auth)
+		} else {
+			// Bearer token (PAT)
+			req.Header.Set("Authorization", "Bearer "+c.APIToken)
+		}
 
-	if c.IsCloud {
-		// Basic auth with email:token
-		auth := base64.StdEncoding.EncodeToString([]byte(c.Email + ":" + c.APIToken))
-		req.Header.Set("Authorization", "Basic "+auth)
-	} else {
-		// Bearer token (PAT)
-		req.Header.Set("Authorization", "Bearer "+c.APIToken)
-	}
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !idempotent {
+				return nil, err
+			}
+			if wait, ok := c.nextRetryDelay(attempt, nil); ok {
+				logWarning("Request failed (%v); retrying in %s", err, wait.Round(time.Millisecond))
+				if err := retrySleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
+		}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		logDebug("Response: %d", resp.StatusCode)
+
+		if resp.StatusCode >= 400 {
+			if isRetryableStatus(resp.StatusCode) && (idempotent || resp.StatusCode == http.StatusTooManyRequests) {
+				if wait, ok := c.nextRetryDelay(attempt, resp.Header); ok {
+					logWarning("API error: %d; retrying in %s", resp.StatusCode, wait.Round(time.Millisecond))
+					if err := retrySleep(ctx, wait); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+			logError("API error: %d - %s", resp.StatusCode, truncate(string(respBody), 500))
+			return nil, &APIError{StatusCode: resp.StatusCode}
+		}
+
+		return respBody, nil
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
+// getJson makes a GET request and returns JSON data
+func (c *JiraClient) getJson(ctx context.Context, endpoint string, params map[string]string) (map[string]any, error) {
+	body, err := c.doRequest(ctx, "GET", endpoint, params, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	logDebug("Response: %d", resp.StatusCode)
-
-	if resp.StatusCode >= 400 {
-		logError("API error: %d - %s", resp.StatusCode, truncate(string(body), 500))
-		return nil, fmt.Errorf("API error: %d", resp.StatusCode)
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
 	}
 
-	return body, nil
+	return result, nil
 }
 
-// getJson makes a GET request and returns JSON data
-func (c *JiraClient) getJson(endpoint string, params map[string]string) (map[string]any, error) {
-	body, err := c.doRequest("GET", endpoint, params)
+// postJson POSTs payload as JSON and returns the JSON response
+func (c *JiraClient) postJson(ctx context.Context, endpoint string, payload any) (map[string]any, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.doRequest(ctx, "POST", endpoint, nil, data)
 	if err != nil {
 		return nil, err
 	}
@@ -799,8 +1173,8 @@ func (c *JiraClient) getJson(endpoint string, params map[string]string) (map[str
 }
 
 // getJsonList makes a GET request and returns a JSON array
-func (c *JiraClient) getJsonList(endpoint string, params map[string]string) ([]map[string]any, error) {
-	body, err := c.doRequest("GET", endpoint, params)
+func (c *JiraClient) getJsonList(ctx context.Context, endpoint string, params map[string]string) ([]map[string]any, error) {
+	body, err := c.doRequest(ctx, "GET", endpoint, params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -70,6 +79,20 @@ func TestExtractIssueData_nativeDueDate(t *testing.T) {
 	}
 }
 
+func TestExtractIssueData_nativeParent(t *testing.T) {
+	issue := map[string]any{
+		"key": "TM-5",
+		"fields": map[string]any{
+			"summary": "Story in a team-managed project",
+			"parent":  map[string]any{"id": "10001", "key": "TM-1", "fields": map[string]any{"summary": "Epic"}},
+		},
+	}
+	data := testJiraClientForExtract(nil, nil).extractIssueData(issue)
+	if data.ParentKey != "TM-1" {
+		t.Errorf("ParentKey = %q, want TM-1", data.ParentKey)
+	}
+}
+
 func TestExtractIssueData_missingFields(t *testing.T) {
 	issue := map[string]any{
 		"key": "PROJ-2",
@@ -238,6 +261,98 @@ func TestExtractIssueData_trendingFromJiraField(t *testing.T) {
 	}
 }
 
+func TestExtractIssueData_writeTrendingIgnoresJiraField(t *testing.T) {
+	issue := map[string]any{
+		"key": "P-1",
+		"fields": map[string]any{
+			"summary":         "X",
+			"status":          map[string]any{"name": "Closed"},
+			"customfield_999": map[string]any{"value": "Off track"},
+		},
+	}
+	cfg := &ReportConfig{TrendingStatusFieldName: "Delivery health", WriteTrending: true}
+	data := testJiraClientForExtract(cfg, map[string]string{"Delivery health": "customfield_999"}).extractIssueData(issue)
+	if data.Trending != "" || data.TrendingField != "Off track" {
+		t.Errorf("Trending=%q TrendingField=%q, want computed trending and the raw field kept", data.Trending, data.TrendingField)
+	}
+	computeTrending(data, false)
+	if data.Trending != "done" {
+		t.Errorf("computed Trending = %q, want done", data.Trending)
+	}
+}
+
+func TestExtractIssueData_blockingLinks(t *testing.T) {
+	issue := map[string]any{
+		"key": "P-1",
+		"fields": map[string]any{
+			"summary": "X",
+			"status":  map[string]any{"name": "In Progress"},
+			"issuelinks": []any{
+				map[string]any{"type": map[string]any{"name": "Blocks", "inward": "is blocked by", "outward": "blocks"},
+					"outwardIssue": map[string]any{"key": "P-2", "fields": map[string]any{"summary": "Downstream"}}},
+				map[string]any{"type": map[string]any{"name": "Blocks", "inward": "is blocked by", "outward": "blocks"},
+					"inwardIssue": map[string]any{"key": "P-3"}},
+				map[string]any{"type": map[string]any{"name": "Relates", "inward": "relates to", "outward": "relates to"},
+					"outwardIssue": map[string]any{"key": "P-4"}},
+			},
+		},
+	}
+	data := testJiraClientForExtract(nil, nil).extractIssueData(issue)
+	if len(data.Blocks) != 1 || data.Blocks[0] != (LinkedIssue{Key: "P-2", Summary: "Downstream"}) {
+		t.Errorf("Blocks = %+v", data.Blocks)
+	}
+	if len(data.BlockedBy) != 1 || data.BlockedBy[0].Key != "P-3" {
+		t.Errorf("BlockedBy = %+v", data.BlockedBy)
+	}
+}
+
+func TestExtractIssueData_extraFields(t *testing.T) {
+	issue := map[string]any{
+		"key": "P-1",
+		"fields": map[string]any{
+			"summary":          "X",
+			"status":           map[string]any{"name": "In Progress"},
+			"labels":           []any{"backend", "q3"},
+			"customfield_1001": 5.0,
+			"customfield_1002": []any{"com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=7,rapidViewId=2,state=ACTIVE,name=Sprint 7,startDate=2025-01-01]"},
+		},
+	}
+	cfg := &ReportConfig{ExtraFields: []string{"labels", "Story Points", "Sprint", "Missing"}}
+	nameToID := map[string]string{"labels": "labels", "Story Points": "customfield_1001", "Sprint": "customfield_1002"}
+	data := testJiraClientForExtract(cfg, nameToID).extractIssueData(issue)
+	want := map[string]string{"labels": "backend, q3", "Story Points": "5", "Sprint": "Sprint 7"}
+	for name, v := range want {
+		if data.Extra[name] != v {
+			t.Errorf("Extra[%q] = %q, want %q", name, data.Extra[name], v)
+		}
+	}
+	if _, ok := data.Extra["Missing"]; ok {
+		t.Error("unresolved field should not be in Extra")
+	}
+}
+
+func TestExtractIssueData_extraOnlyRequestedFields(t *testing.T) {
+	issue := map[string]any{
+		"key": "P-1",
+		"fields": map[string]any{
+			"customfield_1": "2025-06-01",
+			"customfield_2": "E-1",
+			"customfield_3": 3.0,
+		},
+	}
+	nameToID := map[string]string{"Target end": "customfield_1", "Epic Link": "customfield_2", "Story Points": "customfield_3"}
+	cfg := &ReportConfig{DueDateFieldName: "Target end"}
+	if data := testJiraClientForExtract(cfg, nameToID).extractIssueData(issue); data.Extra != nil {
+		t.Errorf("Extra = %v, want nil without --field, --columns or --sort", data.Extra)
+	}
+
+	cfg = &ReportConfig{DueDateFieldName: "Target end", Columns: []string{"key", "Epic Link"}, SortKeys: []SortKey{{Field: "Story Points"}}}
+	data := testJiraClientForExtract(cfg, nameToID).extractIssueData(issue)
+	if len(data.Extra) != 2 || data.Extra["Epic Link"] != "E-1" || data.Extra["Story Points"] != "3" {
+		t.Errorf("Extra = %v, want the --columns and --sort custom fields", data.Extra)
+	}
+}
+
 func TestExtractIssueData_statusNormalized(t *testing.T) {
 	issue := map[string]any{
 		"key": "P-1",
@@ -256,63 +371,35 @@ func TestExtractIssueData_statusNormalized(t *testing.T) {
 	}
 }
 
-func TestComputeTrending_includeChildren(t *testing.T) {
-	parentWithResolvedChildren := func() *IssueData {
-		return &IssueData{
-			Key:    "P-1",
-			Status: "in progress",
-			Children: []*IssueData{
-				{Key: "C-1", Status: "resolved"},
-				{Key: "C-2", Status: "resolved"},
-			},
-		}
+func TestExtractIssueData_statusCategoryFallback(t *testing.T) {
+	tests := []struct {
+		name, category, wantStatus, wantTrending string
+	}{
+		{"In Review", "indeterminate", "in progress", "on track"},
+		{"QA", "indeterminate", "in progress", "on track"},
+		{"Done", "done", "resolved", "done"},
+		{"Won't Do", "done", "resolved", "done"},
+		{"Backlog", "new", "new", "not started"},
+		{"Mystery", "undefined", "unknown", "unknown"},
+		{"Blocked", "indeterminate", "blocked", "off track"}, // known names win over the category
 	}
-
-	t.Run("false leaves children uncomputed and parent on track", func(t *testing.T) {
-		parent := parentWithResolvedChildren()
-		computeTrending(parent, false)
-		if parent.Trending != "on track" {
-			t.Errorf("parent Trending = %q, want on track", parent.Trending)
-		}
-		for _, child := range parent.Children {
-			if child.Trending != "" {
-				t.Errorf("child %s Trending = %q, want empty when includeChildren is false", child.Key, child.Trending)
-			}
-		}
-	})
-
-	t.Run("true computes children and rolls up when all done", func(t *testing.T) {
-		parent := parentWithResolvedChildren()
-		computeTrending(parent, true)
-		if parent.Trending != "done" {
-			t.Errorf("parent Trending = %q, want done", parent.Trending)
-		}
-		if parent.TrendingComment != "All children are done. What's left?" {
-			t.Errorf("parent TrendingComment = %q, want all-children-done message", parent.TrendingComment)
-		}
-		for _, child := range parent.Children {
-			if child.Trending != "done" || child.TrendingEmoji != "🟣" {
-				t.Errorf("child %s trending = %q emoji = %q, want done/🟣", child.Key, child.Trending, child.TrendingEmoji)
-			}
-		}
-	})
-
-	t.Run("true propagates child off track to parent", func(t *testing.T) {
-		parent := &IssueData{
-			Key:    "P-1",
-			Status: "in progress",
-			Children: []*IssueData{
-				{Key: "C-1", Status: "blocked"},
+	for _, tt := range tests {
+		issue := map[string]any{
+			"key": "P-1",
+			"fields": map[string]any{
+				"summary": "Test",
+				"status":  map[string]any{"name": tt.name, "statusCategory": map[string]any{"key": tt.category}},
 			},
 		}
-		computeTrending(parent, true)
-		if parent.Trending != "off track" {
-			t.Errorf("parent Trending = %q, want off track", parent.Trending)
+		data := testJiraClientForExtract(nil, nil).extractIssueData(issue)
+		computeTrending(data, false)
+		if data.Status != tt.wantStatus || data.Trending != tt.wantTrending {
+			t.Errorf("%s/%s: Status=%q Trending=%q, want %q/%q", tt.name, tt.category, data.Status, data.Trending, tt.wantStatus, tt.wantTrending)
 		}
-		if parent.Children[0].Trending != "off track" {
-			t.Errorf("child Trending = %q, want off track", parent.Children[0].Trending)
+		if data.StatusName != tt.name {
+			t.Errorf("%s: StatusName = %q", tt.name, data.StatusName)
 		}
-	})
+	}
 }
 
 func TestChildrenJQL_includesHierarchyFieldsWhenResolved(t *testing.T) {
@@ -325,6 +412,7 @@ func TestChildrenJQL_includesHierarchyFieldsWhenResolved(t *testing.T) {
 	}
 	jql := c.childrenJQL("PROJECT-2822")
 	for _, want := range []string{
+		`parent = PROJECT-2822`,
 		`issue in childIssuesOf(PROJECT-2822)`,
 		`issue in linkedIssues(PROJECT-2822, "is parent of")`,
 		`"Epic Link" = PROJECT-2822`,
@@ -353,6 +441,293 @@ func TestChildrenJQL_omitsUnresolvedHierarchyFields(t *testing.T) {
 	}
 }
 
+func TestChildrenJQL_customLinkTypesAndHierarchyFields(t *testing.T) {
+	c := &JiraClient{
+		customFieldNameToID: map[string]string{"Feature Link": "customfield_10050"},
+		customFieldsLoaded:  true,
+		childLinkTypes:      []string{"implements", `has "sub" work`},
+		hierarchyFieldNames: []string{"Feature Link"},
+		noChildIssuesOf:     true,
+	}
+	got := c.childrenJQL("PROJECT-7")
+	want := `parent = PROJECT-7 OR issue in linkedIssues(PROJECT-7, "implements") OR issue in linkedIssues(PROJECT-7, "has \"sub\" work") OR "Feature Link" = PROJECT-7`
+	if got != want {
+		t.Errorf("childrenJQL:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestChildrenJQL_emptyLinkTypesDisableLinks(t *testing.T) {
+	c := &JiraClient{customFieldsLoaded: true, childLinkTypes: []string{}, hierarchyFieldNames: []string{}}
+	if got := c.childrenJQL("PROJECT-1"); got != `parent = PROJECT-1 OR issue in childIssuesOf(PROJECT-1)` {
+		t.Errorf("childrenJQL = %s", got)
+	}
+}
+
+func TestPrepareFieldResolution_childDiscovery(t *testing.T) {
+	c := &JiraClient{}
+	c.prepareFieldResolution(&ReportConfig{HierarchyFieldNames: []string{"Feature Link"}, ChildLinkTypes: []string{"implements"}})
+	if _, ok := c.customFieldNameToID["Feature Link"]; !ok {
+		t.Error("configured hierarchy field should be resolved")
+	}
+	if _, ok := c.customFieldNameToID[defaultEpicLinkFieldName]; ok {
+		t.Error("default hierarchy fields should not be resolved when overridden")
+	}
+	if got := c.childLinks(); len(got) != 1 || got[0] != "implements" {
+		t.Errorf("childLinks = %v", got)
+	}
+}
+
+// fakeSearchIssues returns n minimal issue blobs keyed FAKE-1..FAKE-n.
+func fakeSearchIssues(from, to int) []map[string]any {
+	var out []map[string]any
+	for i := from; i < to; i++ {
+		out = append(out, map[string]any{"key": fmt.Sprintf("FAKE-%d", i+1), "fields": map[string]any{"summary": "s"}})
+	}
+	return out
+}
+
+func TestSearchPages_offsetPaginationOnServer(t *testing.T) {
+	const total = 120
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		json.NewEncoder(w).Encode(map[string]any{
+			"total":  total,
+			"issues": fakeSearchIssues(startAt, min(startAt+size, total)),
+		})
+	}))
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	got, truncated, err := c.searchPages(context.Background(), "project = X", "summary", 1000)
+	if err != nil {
+		t.Fatalf("searchPages: %v", err)
+	}
+	if len(got) != total || truncated {
+		t.Errorf("got %d issues (truncated=%v), want %d", len(got), truncated, total)
+	}
+	for _, p := range paths {
+		if p != "/rest/api/2/search" {
+			t.Errorf("server search should use /rest/api/2/search, got %s", p)
+		}
+	}
+	if len(paths) != 3 {
+		t.Errorf("requests = %d, want 3 pages of %d", len(paths), defaultPageSize)
+	}
+
+	got, truncated, err = c.searchPages(context.Background(), "project = X", "summary", 100)
+	if err != nil {
+		t.Fatalf("searchPages: %v", err)
+	}
+	if len(got) != 100 || !truncated {
+		t.Errorf("capped search: got %d issues (truncated=%v), want 100 truncated", len(got), truncated)
+	}
+
+	got, truncated, err = c.searchPages(context.Background(), "project = X", "summary", 0)
+	if err != nil {
+		t.Fatalf("searchPages: %v", err)
+	}
+	if len(got) != total || truncated {
+		t.Errorf("unlimited search: got %d issues (truncated=%v), want %d", len(got), truncated, total)
+	}
+}
+
+func TestSearchPages_tokenPaginationOnCloud(t *testing.T) {
+	const total = 120
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		q := r.URL.Query()
+		if q.Get("startAt") != "" {
+			t.Errorf("enhanced search must not send startAt")
+		}
+		start := 0
+		if tok := q.Get("nextPageToken"); tok != "" {
+			start, _ = strconv.Atoi(strings.TrimPrefix(tok, "tok-"))
+		}
+		size, _ := strconv.Atoi(q.Get("maxResults"))
+		end := min(start+size, total)
+		resp := map[string]any{"issues": fakeSearchIssues(start, end), "isLast": end >= total}
+		if end < total {
+			resp["nextPageToken"] = fmt.Sprintf("tok-%d", end)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	c.IsCloud = true
+	c.APIVersion = "3"
+	got, truncated, err := c.searchPages(context.Background(), "project = X", "summary", 1000)
+	if err != nil {
+		t.Fatalf("searchPages: %v", err)
+	}
+	if len(got) != total || truncated {
+		t.Errorf("got %d issues (truncated=%v), want %d", len(got), truncated, total)
+	}
+	if got[total-1]["key"] != fmt.Sprintf("FAKE-%d", total) {
+		t.Errorf("last key = %v", got[total-1]["key"])
+	}
+	for _, p := range paths {
+		if p != "/rest/api/3/search/jql" {
+			t.Errorf("cloud search should use /rest/api/3/search/jql, got %s", p)
+		}
+	}
+
+	// maxResults still caps the result even though the server has more pages.
+	paths = nil
+	got, truncated, err = c.searchPages(context.Background(), "project = X", "summary", 60)
+	if err != nil {
+		t.Fatalf("searchPages: %v", err)
+	}
+	if len(got) != 60 || len(paths) != 2 || !truncated {
+		t.Errorf("got %d issues in %d requests (truncated=%v), want 60 in 2, truncated", len(got), len(paths), truncated)
+	}
+}
+
+func TestGetMostRecentCommentsBulk_cloudUsesEnhancedSearch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/3/search/jql" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("fields") != "comment" {
+			t.Errorf("fields = %q, want comment", r.URL.Query().Get("fields"))
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"isLast": true,
+			"issues": []any{map[string]any{
+				"key": "A-1",
+				"fields": map[string]any{"comment": map[string]any{"comments": []any{
+					map[string]any{"id": "1", "created": "2025-01-01T00:00:00.000Z"},
+					map[string]any{"id": "2", "created": "2025-02-01T00:00:00.000Z"},
+				}}},
+			}},
+		})
+	}))
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	c.IsCloud = true
+	c.APIVersion = "3"
+	got, err := c.getMostRecentCommentsBulk(context.Background(), []*IssueData{{Key: "A-1"}, {Key: "A-2"}})
+	if err != nil {
+		t.Fatalf("getMostRecentCommentsBulk: %v", err)
+	}
+	if getString(got["A-1"], "id", "") != "2" {
+		t.Errorf("latest comment = %v, want id 2", got["A-1"])
+	}
+	if _, ok := got["A-2"]; ok {
+		t.Error("issues without comments should be omitted")
+	}
+}
+
+func TestLoadChildren_cancelledContext(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"total": 0, "issues": []any{}})
+	}))
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	c.customFieldsLoaded = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	parents := []*IssueData{{Key: "P-1"}, {Key: "P-2"}}
+	if err := c.loadChildren(ctx, parents, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("no searches should run after cancellation, got %d", calls.Load())
+	}
+}
+
+// fakeHierarchyServer answers child JQL searches from a parent -> children key map.
+func fakeHierarchyServer(t *testing.T, tree map[string][]string) *httptest.Server {
+	t.Helper()
+	re := regexp.MustCompile(`childIssuesOf\(([^)]+)\)`)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := re.FindStringSubmatch(r.URL.Query().Get("jql"))
+		var issues []any
+		if m != nil {
+			for _, k := range tree[m[1]] {
+				issues = append(issues, map[string]any{"key": k, "fields": map[string]any{"summary": k}})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"total": len(issues), "issues": issues})
+	}))
+}
+
+func TestLoadChildren_depthCyclesAndSharedChildren(t *testing.T) {
+	ts := fakeHierarchyServer(t, map[string][]string{
+		"P-1": {"A-1", "B-1"},
+		"A-1": {"C-1"},
+		"B-1": {"C-1"}, // diamond: C-1 shared by A-1 and B-1
+		"C-1": {"P-1"}, // cycle back to the root
+	})
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	c.customFieldsLoaded = true
+
+	root := &IssueData{Key: "P-1"}
+	if err := c.loadChildren(context.Background(), []*IssueData{root}, 1); err != nil {
+		t.Fatalf("loadChildren depth 1: %v", err)
+	}
+	if len(root.Children) != 2 || root.Children[0].Children != nil {
+		t.Fatalf("depth 1 should load exactly one level: %+v", root.Children)
+	}
+
+	root = &IssueData{Key: "P-1"}
+	if err := c.loadChildren(context.Background(), []*IssueData{root}, 5); err != nil {
+		t.Fatalf("loadChildren depth 5: %v", err)
+	}
+	a, b := root.Children[0], root.Children[1]
+	if a.Key != "A-1" || b.Key != "B-1" {
+		t.Fatalf("level 1 = %s, %s", a.Key, b.Key)
+	}
+	if len(a.Children) != 1 || len(b.Children) != 1 || a.Children[0] != b.Children[0] {
+		t.Fatalf("C-1 should be shared between A-1 and B-1: %+v / %+v", a.Children, b.Children)
+	}
+	if a.ParentKey != "P-1" || a.Children[0].ParentKey != "A-1" {
+		t.Errorf("ParentKey should record the first parent a child was loaded under: A-1=%q C-1=%q", a.ParentKey, a.Children[0].ParentKey)
+	}
+	if got := a.Children[0].Children; len(got) != 0 {
+		t.Errorf("cycle C-1 -> P-1 should be dropped, got %+v", got)
+	}
+
+	// Trending recursion terminates and rolls up from the leaves.
+	a.Children[0].Status = "blocked"
+	root.Status, a.Status, b.Status = "in progress", "in progress", "in progress"
+	computeTrending(root, true)
+	if root.Trending != "off track" {
+		t.Errorf("root Trending = %q, want off track rolled up from C-1", root.Trending)
+	}
+}
+
+func TestLoadChildren_topLevelParentsLinkedToEachOther(t *testing.T) {
+	ts := fakeHierarchyServer(t, map[string][]string{
+		"P-1": {"P-2"},
+		"P-2": {"P-1"},
+	})
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	c.customFieldsLoaded = true
+	p1, p2 := &IssueData{Key: "P-1"}, &IssueData{Key: "P-2"}
+	if err := c.loadChildren(context.Background(), []*IssueData{p1, p2}, 1); err != nil {
+		t.Fatalf("loadChildren: %v", err)
+	}
+	if len(p1.Children) != 1 || p1.Children[0] != p2 {
+		t.Errorf("P-2 should be attached to P-1 as the same issue: %+v", p1.Children)
+	}
+	if len(p2.Children) != 0 {
+		t.Errorf("P-1 under P-2 would be a cycle: %+v", p2.Children)
+	}
+}
+
 // copyMap does a shallow copy of a map for building test variants.
 func copyMap(m map[string]any) map[string]any {
 	out := make(map[string]any, len(m))
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// reportColumnNames are the built-in --columns and --sort names (CSV header spellings where they exist).
+var reportColumnNames = []string{
+	"key", "url", "issue", "summary", "type", "status", "status_emoji",
+	"trending", "trending_emoji", "trending_comment", "assignee", "priority",
+	"created", "updated", "due", "due_slips", "original_due", "last_update", "comment_url",
+	"parent", "history", "rollup",
+}
+
+// columnAliases maps the markdown header spellings (and CSV's target_end) to column names.
+var columnAliases = map[string]string{
+	"due date":    "due",
+	"target_end":  "due",
+	"comment":     "trending_comment",
+	"last update": "last_update",
+}
+
+// columnFormat selects how columnCell renders a value.
+type columnFormat int
+
+const (
+	columnMarkdown columnFormat = iota
+	columnText
+	columnCSV
+)
+
+// customFieldNames returns the custom field display names resolved for this report; their values
+// (IssueData.Extra) can be selected as columns and sort keys.
+func (c *ReportConfig) customFieldNames() []string {
+	var names []string
+	for _, name := range []string{c.DueDateFieldName, c.TrendingStatusFieldName} {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	hierarchy := c.HierarchyFieldNames
+	if hierarchy == nil {
+		hierarchy = defaultHierarchyFieldNames()
+	}
+	names = append(names, hierarchy...)
+	return append(names, c.ExtraFields...)
+}
+
+// extraFieldNames returns the custom fields whose values are kept in IssueData.Extra, sorted: the --field
+// names and any custom field selected by --columns or --sort.
+func (c *ReportConfig) extraFieldNames() []string {
+	seen := map[string]bool{}
+	for _, name := range c.ExtraFields {
+		if name = strings.TrimSpace(name); name != "" {
+			seen[name] = true
+		}
+	}
+	fields := append([]string(nil), c.Columns...)
+	for _, k := range c.SortKeys {
+		fields = append(fields, k.Field)
+	}
+	for _, field := range fields {
+		if !slices.Contains(reportColumnNames, field) {
+			seen[field] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveColumnName returns the column for a --columns or --sort name: a built-in column or alias, or a
+// custom field display name (matched case-insensitively and returned as configured).
+func resolveColumnName(name string, customFields []string) (string, error) {
+	name = strings.TrimSpace(name)
+	lower := strings.ToLower(name)
+	if alias, ok := columnAliases[lower]; ok {
+		return alias, nil
+	}
+	for _, c := range reportColumnNames {
+		if lower == c {
+			return c, nil
+		}
+	}
+	for _, f := range customFields {
+		if strings.EqualFold(name, f) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown column %q (use %s, or a custom field name: %s)",
+		name, strings.Join(reportColumnNames, ", "), strings.Join(customFields, ", "))
+}
+
+// parseColumns parses --columns, a comma-separated list of column names.
+func parseColumns(s string, customFields []string) ([]string, error) {
+	var columns []string
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		column, err := resolveColumnName(part, customFields)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// SortKey is one --sort term: a column, descending when Desc.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+func (k SortKey) String() string {
+	if k.Desc {
+		return k.Field + ":desc"
+	}
+	return k.Field + ":asc"
+}
+
+// parseSortKeys parses --sort, e.g. "due:asc,priority:desc" (the direction defaults to asc).
+func parseSortKeys(s string, customFields []string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, dir, _ := strings.Cut(part, ":")
+		field, err := resolveColumnName(name, customFields)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(strings.TrimSpace(dir)) {
+		case "", "asc":
+			keys = append(keys, SortKey{Field: field})
+		case "desc":
+			keys = append(keys, SortKey{Field: field, Desc: true})
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q for %s (use asc or desc)", dir, field)
+		}
+	}
+	return keys, nil
+}
+
+// sortValue returns how an issue sorts on field: a rank for ordered values (status, trending,
+// priority, due slips), else a lowercased string (compared as numbers when both parse); empty values
+// always sort last.
+func sortValue(issue *IssueData, field string) (rank int, s string, empty bool) {
+	switch field {
+	case "status":
+		return activeStatusMap.forIssue(issue).Priority, "", false
+	case "trending":
+		if r, ok := htmlTrendingOrder[issue.Trending]; ok {
+			return r, "", false
+		}
+		return len(htmlTrendingOrder), issue.Trending, issue.Trending == ""
+	case "priority":
+		if issue.Priority == "" || issue.Priority == "None" {
+			return 0, "", true
+		}
+		if r, ok := jiraPriorityOrder[strings.ToLower(issue.Priority)]; ok {
+			return r, "", false
+		}
+		return len(jiraPriorityOrder), strings.ToLower(issue.Priority), false
+	case "due_slips":
+		return dueSlipCount(issue), "", issue.DueSlips == nil
+	case "due":
+		return 0, issue.Due, issue.Due == ""
+	case "last_update":
+		return 0, issue.Comment.Created, issue.Comment.Created == ""
+	}
+	s = strings.ToLower(columnCell(field, issue, nil, columnCSV))
+	return 0, s, s == "" || s == "n/a"
+}
+
+// lessBySortKeys reports whether a sorts before b on keys; ties keep the existing order. numeric[i]
+// compares keys[i] as numbers (see numericSortKeys), so every column uses one consistent order.
+func lessBySortKeys(a, b *IssueData, keys []SortKey, numeric []bool) bool {
+	for i, k := range keys {
+		ra, sa, ea := sortValue(a, k.Field)
+		rb, sb, eb := sortValue(b, k.Field)
+		if ea || eb {
+			if ea != eb {
+				return eb
+			}
+			continue
+		}
+		var c int
+		if numeric[i] {
+			fa, _ := strconv.ParseFloat(sa, 64)
+			fb, _ := strconv.ParseFloat(sb, 64)
+			c = cmp.Compare(fa, fb) // numeric fields such as story points
+		} else if c = cmp.Compare(ra, rb); c == 0 {
+			c = strings.Compare(sa, sb)
+		}
+		if c == 0 {
+			continue
+		}
+		if k.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// numericSortKeys reports, per key, whether its column is numeric: it has values and every non-empty
+// value parses as a number. A column with any other value sorts as text throughout.
+func numericSortKeys(issues []*IssueData, keys []SortKey) []bool {
+	numeric := make([]bool, len(keys))
+	for i, k := range keys {
+		for _, issue := range issues {
+			_, s, empty := sortValue(issue, k.Field)
+			if empty {
+				continue
+			}
+			if _, err := strconv.ParseFloat(s, 64); err != nil {
+				numeric[i] = false
+				break
+			}
+			numeric[i] = true
+		}
+	}
+	return numeric
+}
+
+// sortBySortKeys re-sorts issues (already in the default order) by cfg.SortKeys (--sort).
+func sortBySortKeys(issues []*IssueData, keys []SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	numeric := numericSortKeys(issues, keys)
+	sort.SliceStable(issues, func(i, j int) bool { return lessBySortKeys(issues[i], issues[j], keys, numeric) })
+}
+
+// columnCell renders one --columns cell. Markdown links keys and summaries and escapes free text;
+// cfg may be nil when only the raw value is needed (history then renders empty).
+func columnCell(column string, issue *IssueData, cfg *ReportConfig, format columnFormat) string {
+	md := format == columnMarkdown
+	text := func(s string) string {
+		s = strings.ReplaceAll(s, "\n", " ")
+		if md {
+			return escapeMarkdownInline(s)
+		}
+		return s
+	}
+	switch column {
+	case "key":
+		if md && issue.URL != "" {
+			return fmt.Sprintf("[%s](%s)", issue.Key, issue.URL)
+		}
+		return issue.Key
+	case "url":
+		return issue.URL
+	case "issue":
+		if md {
+			return fmt.Sprintf("[%s](%s)", escapeMarkdownInline(issue.Summary), issue.URL)
+		}
+		return text(issue.Summary)
+	case "summary":
+		return text(issue.Summary)
+	case "type":
+		return issue.Type
+	case "status":
+		return statusForDisplay(issue)
+	case "status_emoji":
+		return issue.StatusEmoji
+	case "trending":
+		if format == columnCSV {
+			return issue.Trending
+		}
+		return strings.TrimSpace(issue.TrendingEmoji + " " + issue.Trending)
+	case "trending_emoji":
+		return issue.TrendingEmoji
+	case "trending_comment":
+		return text(trendingCommentForDisplay(issue.TrendingComment))
+	case "assignee":
+		return issue.Assignee
+	case "priority":
+		return issue.Priority
+	case "created":
+		if format == columnCSV {
+			return issue.Created
+		}
+		return FormatDate(issue.Created)
+	case "updated":
+		if format == columnCSV {
+			return issue.Updated
+		}
+		return FormatDate(issue.Updated)
+	case "due":
+		if format == columnCSV {
+			return FormatDate(issue.Due)
+		}
+		return dueDateWithSlips(issue)
+	case "due_slips":
+		if issue.DueSlips == nil {
+			return ""
+		}
+		return strconv.Itoa(*issue.DueSlips)
+	case "original_due":
+		return issue.OriginalDue
+	case "last_update":
+		if md {
+			return FormatTimestampWithLink(issue.Comment.Created, issue.Comment.Url, false)
+		}
+		return FormatDate(issue.Comment.Created)
+	case "comment_url":
+		return issue.Comment.Url
+	case "parent":
+		if md {
+			return parentLink(issue)
+		}
+		return issue.ParentKey
+	case "history":
+		if cfg == nil {
+			return ""
+		}
+		return trendingSparkline(issue, cfg)
+	case "rollup":
+		return trendingRollup(issue)
+	}
+	return text(issue.Extra[column])
+}
+
+// treeColumn returns the column that --tree indents: the first of issue, key or summary.
+func treeColumn(columns []string) string {
+	for _, c := range columns {
+		if c == "issue" || c == "key" || c == "summary" {
+			return c
+		}
+	}
+	return ""
+}
+
+// columnLink returns the URL a --columns cell links to in the HTML, Confluence and Jira reports; "" for
+// columns that are plain text.
+func columnLink(column string, issue *IssueData) string {
+	switch column {
+	case "key", "url", "issue":
+		return issue.URL
+	case "last_update", "comment_url":
+		return issue.Comment.Url
+	case "parent":
+		return parentURL(issue)
+	}
+	return ""
+}
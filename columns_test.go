@@ -0,0 +1,168 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseColumnsAndSortKeys(t *testing.T) {
+	custom := []string{"Story Points", "Epic Link"}
+	cols, err := parseColumns(" key, Summary,due date,story points ", custom)
+	if err != nil || strings.Join(cols, "|") != "key|summary|due|Story Points" {
+		t.Errorf("columns = %q, %v", cols, err)
+	}
+	if _, err := parseColumns("key,velocity", custom); err == nil {
+		t.Error("unknown column should be an error")
+	}
+
+	keys, err := parseSortKeys("due:asc,priority:DESC,assignee", custom)
+	if err != nil || len(keys) != 3 || keys[0] != (SortKey{Field: "due"}) || keys[1] != (SortKey{Field: "priority", Desc: true}) || keys[2].Desc {
+		t.Errorf("sort keys = %v, %v", keys, err)
+	}
+	if _, err := parseSortKeys("due:sideways", custom); err == nil {
+		t.Error("invalid direction should be an error")
+	}
+}
+
+func TestSortBySortKeys(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "A-1", Due: "2025-03-01", Priority: "Low"},
+		{Key: "A-2", Due: "", Priority: "Highest"},
+		{Key: "A-3", Due: "2025-03-01", Priority: "High"},
+		{Key: "A-4", Due: "2025-01-01", Priority: "None"},
+	}
+	sortBySortKeys(issues, []SortKey{{Field: "due"}, {Field: "priority", Desc: true}})
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Key)
+	}
+	// due ascending (empty last); ties by priority descending, i.e. Low before High
+	if strings.Join(got, ",") != "A-4,A-1,A-3,A-2" {
+		t.Errorf("order = %v", got)
+	}
+
+	sortBySortKeys(issues, []SortKey{{Field: "priority"}})
+	if issues[0].Key != "A-2" || issues[3].Key != "A-4" {
+		t.Errorf("priority ascending should put Highest first and None last: %v", issues)
+	}
+}
+
+func TestSortBySortKeys_numericExtraField(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "A-1", Extra: map[string]string{"Story Points": "13"}},
+		{Key: "A-2"},
+		{Key: "A-3", Extra: map[string]string{"Story Points": "5"}},
+		{Key: "A-4", Extra: map[string]string{"Story Points": "8"}},
+	}
+	sortBySortKeys(issues, []SortKey{{Field: "Story Points", Desc: true}})
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Key)
+	}
+	if strings.Join(got, ",") != "A-1,A-4,A-3,A-2" {
+		t.Errorf("order = %v, want numeric descending with the empty value last", got)
+	}
+}
+
+func TestSortBySortKeys_mixedValuesSortAsText(t *testing.T) {
+	points := func(keys ...string) []*IssueData {
+		var issues []*IssueData
+		for _, k := range keys {
+			key, v, _ := strings.Cut(k, "=")
+			issues = append(issues, &IssueData{Key: key, Extra: map[string]string{"Story Points": v}})
+		}
+		return issues
+	}
+	order := func(issues []*IssueData) string {
+		var got []string
+		for _, issue := range issues {
+			got = append(got, issue.Key)
+		}
+		return strings.Join(got, ",")
+	}
+
+	// one non-numeric value makes the whole column text, whatever order the values arrive in
+	for _, issues := range [][]*IssueData{
+		points("A-1=3", "A-2=10", "A-3=n/a-ish", "A-4=5"),
+		points("A-3=n/a-ish", "A-4=5", "A-1=3", "A-2=10"),
+	} {
+		sortBySortKeys(issues, []SortKey{{Field: "Story Points"}})
+		if got := order(issues); got != "A-2,A-1,A-4,A-3" {
+			t.Errorf("order = %s, want text order 10,3,5,n/a-ish", got)
+		}
+	}
+
+	// numerically equal values tie and keep their order, in both directions
+	issues := points("A-1=5", "A-2=5.0", "A-3=2")
+	sortBySortKeys(issues, []SortKey{{Field: "Story Points", Desc: true}})
+	if got := order(issues); got != "A-1,A-2,A-3" {
+		t.Errorf("order = %s, want A-1,A-2,A-3", got)
+	}
+}
+
+func TestRenderReports_columns(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "A-1", URL: "https://jira/browse/A-1", Summary: "First | one", Status: "in progress", Assignee: "Ann", Due: "2025-03-01",
+			Trending: "on track", TrendingEmoji: "🟢", Priority: "High", Extra: map[string]string{"Story Points": "5"}},
+		{Key: "A-2", URL: "https://jira/browse/A-2", Summary: "Second", Status: "in progress", Assignee: "Bob", Due: "2025-02-01",
+			Trending: "at risk", TrendingEmoji: "🟡", Priority: "Low"},
+	}
+	cfg := &ReportConfig{Title: "Cols", Columns: []string{"key", "summary", "due", "Story Points"}, SortKeys: []SortKey{{Field: "assignee", Desc: true}}}
+
+	md := RenderMarkdownReport(issues, cfg)
+	for _, want := range []string{
+		"| key | summary | due | Story Points |\n|---|---|---|---|",
+		"| [A-2](https://jira/browse/A-2) | Second | 2025-02-01 |  |\n| [A-1](https://jira/browse/A-1) | First \\| one | 2025-03-01 | 5 |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	csv := RenderCSVReport(issues, cfg)
+	if want := "key🐱summary🐱due🐱Story Points\nA-2🐱Second🐱2025-02-01🐱\nA-1🐱First | one🐱2025-03-01🐱5"; csv != want {
+		t.Errorf("csv = %q, want %q", csv, want)
+	}
+
+	simple := RenderSimpleReport(issues, cfg)
+	lines := strings.Split(simple, "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "key") || !strings.HasSuffix(lines[0], "Story Points") ||
+		!strings.HasPrefix(lines[1], "A-2") || !strings.HasSuffix(lines[2], "5") {
+		t.Errorf("simple = %q", simple)
+	}
+	html := RenderHTMLReport(issues, cfg)
+	for _, want := range []string{
+		"<tr><th>key</th><th>summary</th><th>due</th><th>Story Points</th></tr>",
+		`<tr><td><a href="https://jira/browse/A-2">A-2</a></td><td>Second</td><td data-sort="2025-02-01">2025-02-01</td><td></td></tr>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<th>trending</th>") {
+		t.Errorf("html should not keep the fixed columns:\n%s", html)
+	}
+
+	confluence := RenderConfluenceReport(issues, cfg)
+	if want := "<tr><td>" + confluenceJiraMacro("A-1") + "</td><td>First | one</td><td>2025-03-01</td><td>5</td></tr>"; !strings.Contains(confluence, want) {
+		t.Errorf("confluence missing %q:\n%s", want, confluence)
+	}
+
+	wiki := RenderWikiMarkupReport(issues, cfg)
+	for _, want := range []string{
+		"||key||summary||due||Story Points||\n|[A-2|https://jira/browse/A-2]|Second|2025-02-01| |",
+		`|[A-1|https://jira/browse/A-1]|First \| one|2025-03-01|5|`,
+	} {
+		if !strings.Contains(wiki, want) {
+			t.Errorf("wiki missing %q:\n%s", want, wiki)
+		}
+	}
+
+	table := buildADFReport(issues, cfg).Content[2]
+	if len(table.Content) != 3 || len(table.Content[0].Content) != 4 {
+		t.Fatalf("ADF table should have a header and 2 rows of 4 cells: %+v", table)
+	}
+	if link := table.Content[1].Content[0].Content[0].Content[0]; link.Text != "A-2" || len(link.Marks) != 1 {
+		t.Errorf("ADF key cell = %+v", link)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// confluenceStatusColours maps trending values to Confluence status macro colours.
+var confluenceStatusColours = map[string]string{
+	"done":        "Purple",
+	"on track":    "Green",
+	"at risk":     "Yellow",
+	"off track":   "Red",
+	"not started": "Grey",
+}
+
+// confluenceStatusMacro renders a status lozenge for a trending value.
+func confluenceStatusMacro(trending string) string {
+	colour, ok := confluenceStatusColours[trending]
+	if !ok {
+		colour = "Grey"
+	}
+	title := trending
+	if title == "" {
+		title = "unknown"
+	}
+	return fmt.Sprintf(`<ac:structured-macro ac:name="status" ac:schema-version="1"><ac:parameter ac:name="colour">%s</ac:parameter><ac:parameter ac:name="title">%s</ac:parameter></ac:structured-macro>`,
+		colour, html.EscapeString(title))
+}
+
+// confluenceJiraMacro renders a Jira issue macro for key (resolved through Confluence's Jira application link).
+func confluenceJiraMacro(key string) string {
+	return fmt.Sprintf(`<ac:structured-macro ac:name="jira" ac:schema-version="1"><ac:parameter ac:name="key">%s</ac:parameter></ac:structured-macro>`,
+		html.EscapeString(key))
+}
+
+// RenderConfluenceReport renders issues as Confluence storage-format XHTML: a table like the markdown
+// report with status macros for trending and Jira issue macros for keys (--confluence).
+func RenderConfluenceReport(issues []*IssueData, cfg *ReportConfig) string {
+	issues = filterAndSortIssues(issues, cfg)
+	showParent := cfg.RenderChildren
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h2>%s</h2>\n", html.EscapeString(cfg.Title))
+	fmt.Fprintf(&b, "<p>row count: %d · updated %s</p>\n", len(issues), time.Now().Format(time.RFC3339))
+	if text := truncationText(cfg); text != "" {
+		fmt.Fprintf(&b, "<p>⚠️ <strong>truncated:</strong> %s</p>\n", html.EscapeString(text))
+	}
+	if len(cfg.Columns) > 0 {
+		b.WriteString("<table><tbody>\n<tr>")
+		for _, column := range cfg.Columns {
+			fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(column))
+		}
+		b.WriteString("</tr>\n")
+		indented := treeColumn(cfg.Columns)
+		for _, row := range treeRows(issues, cfg.TreeOutput, cfg.SortKeys) {
+			b.WriteString("<tr>")
+			for _, column := range cfg.Columns {
+				cell := confluenceColumnCell(column, row.Issue, cfg)
+				if column == indented {
+					cell = treeIndent(cell, row.Depth, "&nbsp;&nbsp;&nbsp;")
+				}
+				fmt.Fprintf(&b, "<td>%s</td>", cell)
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</tbody></table>")
+		return b.String()
+	}
+	b.WriteString("<table><tbody>\n<tr><th>trending</th><th>type</th><th>status</th><th>issue</th>")
+	if showParent {
+		b.WriteString("<th>parent</th>")
+	}
+	b.WriteString("<th>assignee</th><th>due date</th><th>last update</th><th>comment</th></tr>\n")
+
+	for _, issue := range issues {
+		lastUpdate := "N/A"
+		if issue.Comment.Url != "" {
+			lastUpdate = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(issue.Comment.Url), html.EscapeString(FormatDate(issue.Comment.Created)))
+		}
+		b.WriteString("<tr>")
+		fmt.Fprintf(&b, "<td>%s</td>", confluenceStatusMacro(issue.Trending))
+		fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(issue.Type))
+		fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(statusForDisplay(issue)))
+		fmt.Fprintf(&b, "<td>%s %s</td>", confluenceJiraMacro(issue.Key), html.EscapeString(issue.Summary))
+		if showParent {
+			parent := ""
+			if issue.ParentKey != "" {
+				parent = confluenceJiraMacro(issue.ParentKey)
+			}
+			fmt.Fprintf(&b, "<td>%s</td>", parent)
+		}
+		fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(issue.Assignee))
+		fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(dueDateWithSlips(issue)))
+		fmt.Fprintf(&b, "<td>%s</td>", lastUpdate)
+		fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(trendingCommentForDisplay(issue.TrendingComment)))
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody></table>")
+	return b.String()
+}
+
+// confluenceColumnCell renders one --columns cell as storage format: trending as a status macro, issue and
+// parent keys as Jira macros, other linked columns as links, and escaped text otherwise.
+func confluenceColumnCell(column string, issue *IssueData, cfg *ReportConfig) string {
+	switch column {
+	case "trending":
+		return confluenceStatusMacro(issue.Trending)
+	case "key":
+		return confluenceJiraMacro(issue.Key)
+	case "parent":
+		if issue.ParentKey == "" {
+			return ""
+		}
+		return confluenceJiraMacro(issue.ParentKey)
+	}
+	text := html.EscapeString(columnCell(column, issue, cfg, columnText))
+	if href := columnLink(column, issue); href != "" && text != "" {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(href), text)
+	}
+	return text
+}
+
+// ConfluenceClient updates Confluence pages through the REST API (/rest/api/content). It reuses the
+// Jira client's authentication and retry policy against the Confluence base URL.
+type ConfluenceClient struct {
+	rest *JiraClient
+}
+
+// NewConfluenceClient returns a client for the Confluence at server (e.g. https://x.atlassian.net/wiki).
+// Like Jira, Atlassian Cloud uses basic auth with email:token and Server/Data Center uses a bearer PAT.
+func NewConfluenceClient(server, apiToken, email string) (*ConfluenceClient, error) {
+	if server == "" || apiToken == "" {
+		return nil, fmt.Errorf("CONFLUENCE_SERVER (or JIRA_SERVER) and an API token are required to publish to Confluence")
+	}
+	server = strings.TrimRight(server, "/")
+	isCloud := strings.Contains(strings.ToLower(server), ".atlassian.net")
+	if isCloud && email == "" {
+		return nil, fmt.Errorf("CONFLUENCE_EMAIL (or JIRA_EMAIL) is required for Confluence Cloud authentication")
+	}
+	return &ConfluenceClient{rest: &JiraClient{
+		Server:     server,
+		Email:      email,
+		APIToken:   apiToken,
+		IsCloud:    isCloud,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}}, nil
+}
+
+// loadConfluenceCreds returns CONFLUENCE_SERVER, CONFLUENCE_API_TOKEN and CONFLUENCE_EMAIL, loaded like
+// loadJiraCreds (env, then the creds script). Unset values fall back to the Jira credentials; the server
+// falls back to JIRA_SERVER + "/wiki" on Atlassian Cloud, where one token covers both products.
+func loadConfluenceCreds(credsFilePath string) (server, apiToken, email string, err error) {
+	vals := loadCredsVars(credsFilePath,
+		"CONFLUENCE_SERVER", "CONFLUENCE_API_TOKEN", "CONFLUENCE_EMAIL",
+		"JIRA_SERVER", "JIRA_API_TOKEN", "JIRA_EMAIL")
+	server, apiToken, email = vals["CONFLUENCE_SERVER"], vals["CONFLUENCE_API_TOKEN"], vals["CONFLUENCE_EMAIL"]
+	if server == "" {
+		if jira := strings.TrimRight(vals["JIRA_SERVER"], "/"); strings.Contains(strings.ToLower(jira), ".atlassian.net") {
+			server = jira + "/wiki"
+		}
+	}
+	if apiToken == "" {
+		apiToken = vals["JIRA_API_TOKEN"]
+	}
+	if email == "" {
+		email = vals["JIRA_EMAIL"]
+	}
+
+	if server == "" {
+		return "", "", "", fmt.Errorf("CONFLUENCE_SERVER is not set (set env var or export from ~/.snippets/creds.sh; Cloud defaults to JIRA_SERVER/wiki)")
+	}
+	if apiToken == "" {
+		return "", "", "", fmt.Errorf("CONFLUENCE_API_TOKEN is not set (set env var or export from ~/.snippets/creds.sh; defaults to JIRA_API_TOKEN)")
+	}
+	return server, apiToken, email, nil
+}
+
+// confluencePage is the subset of a Confluence content object needed to update it.
+type confluencePage struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+}
+
+func (c *ConfluenceClient) contentURL(pageID string) string {
+	return fmt.Sprintf("%s/rest/api/content/%s", c.rest.Server, pageID)
+}
+
+// getPage reads a page's title, type and current version.
+func (c *ConfluenceClient) getPage(ctx context.Context, pageID string) (*confluencePage, error) {
+	body, err := c.rest.sendRequest(ctx, "GET", withQueryParams(c.contentURL(pageID), map[string]string{"expand": "version"}), nil)
+	if err != nil {
+		return nil, err
+	}
+	var page confluencePage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// UpdatePage replaces the body of page pageID with storage-format XHTML, keeping its title. Confluence
+// requires the next version number; when someone else saves in between (409 Conflict) the version is
+// re-read and the update retried once. Returns the new version number.
+func (c *ConfluenceClient) UpdatePage(ctx context.Context, pageID, storage string) (int, error) {
+	for attempt := 0; ; attempt++ {
+		page, err := c.getPage(ctx, pageID)
+		if err != nil {
+			return 0, fmt.Errorf("read Confluence page %s: %w", pageID, err)
+		}
+		pageType := page.Type
+		if pageType == "" {
+			pageType = "page"
+		}
+		update := map[string]any{
+			"id":      pageID,
+			"type":    pageType,
+			"title":   page.Title,
+			"version": map[string]any{"number": page.Version.Number + 1, "message": "Updated by snippets"},
+			"body":    map[string]any{"storage": map[string]any{"value": storage, "representation": "storage"}},
+		}
+		payload, err := json.Marshal(update)
+		if err != nil {
+			return 0, err
+		}
+		_, err = c.rest.sendRequest(ctx, "PUT", c.contentURL(pageID), payload)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict && attempt == 0 {
+			logWarning("Confluence page %s changed while publishing; retrying with the latest version", pageID)
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("update Confluence page %s: %w", pageID, err)
+		}
+		return page.Version.Number + 1, nil
+	}
+}
+
+// publishConfluenceReport renders the report in storage format and replaces the body of cfg.ConfluencePageID.
+func publishConfluenceReport(ctx context.Context, parentIssues []*IssueData, cfg *ReportConfig) error {
+	server, apiToken, email, err := loadConfluenceCreds("")
+	if err != nil {
+		return err
+	}
+	client, err := NewConfluenceClient(server, apiToken, email)
+	if err != nil {
+		return err
+	}
+	storage := RenderConfluenceReport(issuesForReport(parentIssues, cfg), cfg)
+	version, err := client.UpdatePage(ctx, cfg.ConfluencePageID, storage)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Published to Confluence page %s (version %d).\n", cfg.ConfluencePageID, version)
+	return nil
+}
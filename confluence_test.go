@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderConfluenceReport_macrosAndEscaping(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "E-1", Summary: "Ship <it> & celebrate", Type: "Epic", Status: "in progress", Trending: "at risk",
+			Assignee: "Ann", Due: "2025-04-01", Comment: IssueComment{Url: "https://jira/c?a=1&b=2", Created: "2025-03-01T00:00:00.000+0000"}},
+		{Key: "E-2", Summary: "Done", Type: "Epic", Status: "closed", Trending: "done", ParentKey: "I-1"},
+	}
+	out := RenderConfluenceReport(issues, &ReportConfig{Title: "Q2 & beyond", RenderChildren: true})
+	for _, want := range []string{
+		"<h2>Q2 &amp; beyond</h2>",
+		`<ac:parameter ac:name="colour">Yellow</ac:parameter><ac:parameter ac:name="title">at risk</ac:parameter>`,
+		`<ac:parameter ac:name="colour">Purple</ac:parameter>`,
+		`<ac:structured-macro ac:name="jira" ac:schema-version="1"><ac:parameter ac:name="key">E-1</ac:parameter></ac:structured-macro> Ship &lt;it&gt; &amp; celebrate`,
+		`<td><ac:structured-macro ac:name="jira" ac:schema-version="1"><ac:parameter ac:name="key">I-1</ac:parameter>`,
+		`<a href="https://jira/c?a=1&amp;b=2">2025-03-01</a>`,
+		"<th>parent</th>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("storage format missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestConfluenceClient_UpdatePageBumpsVersion(t *testing.T) {
+	noRetrySleep(t)
+	version, puts := 7, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/content/123" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer pat" {
+			t.Errorf("Authorization = %q", got)
+		}
+		switch r.Method {
+		case "GET":
+			if r.URL.Query().Get("expand") != "version" {
+				t.Errorf("expand = %q", r.URL.Query().Get("expand"))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "123", "type": "page", "title": "Status", "version": map[string]any{"number": version}})
+		case "PUT":
+			puts++
+			var body struct {
+				Title   string `json:"title"`
+				Version struct {
+					Number int `json:"number"`
+				} `json:"version"`
+				Body struct {
+					Storage struct {
+						Value          string `json:"value"`
+						Representation string `json:"representation"`
+					} `json:"storage"`
+				} `json:"body"`
+			}
+			data, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Fatalf("PUT body: %v", err)
+			}
+			if puts == 1 {
+				version = 8 // someone else saved first
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if body.Title != "Status" || body.Version.Number != 9 || body.Body.Storage.Representation != "storage" || body.Body.Storage.Value != "<p>hi</p>" {
+				t.Errorf("PUT body = %+v", body)
+			}
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := NewConfluenceClient(ts.URL+"/wiki/", "pat", "")
+	if err != nil {
+		t.Fatalf("NewConfluenceClient: %v", err)
+	}
+	c.rest.HTTPClient = ts.Client()
+	got, err := c.UpdatePage(context.Background(), "123", "<p>hi</p>")
+	if err != nil {
+		t.Fatalf("UpdatePage: %v", err)
+	}
+	if got != 9 || puts != 2 {
+		t.Errorf("version = %d after %d PUTs, want 9 after a conflict retry", got, puts)
+	}
+}
+
+func TestLoadConfluenceCreds_fallsBackToJira(t *testing.T) {
+	for _, name := range []string{"CONFLUENCE_SERVER", "CONFLUENCE_API_TOKEN", "CONFLUENCE_EMAIL"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("JIRA_SERVER", "https://acme.atlassian.net/")
+	t.Setenv("JIRA_API_TOKEN", "jira-token")
+	t.Setenv("JIRA_EMAIL", "me@acme.com")
+	noCreds := filepath.Join(t.TempDir(), "creds.sh")
+
+	server, token, email, err := loadConfluenceCreds(noCreds)
+	if err != nil || server != "https://acme.atlassian.net/wiki" || token != "jira-token" || email != "me@acme.com" {
+		t.Errorf("cloud fallback: %q %q %q %v", server, token, email, err)
+	}
+
+	t.Setenv("JIRA_SERVER", "https://jira.corp.example.com")
+	if _, _, _, err := loadConfluenceCreds(noCreds); err == nil {
+		t.Error("Server/Data Center needs an explicit CONFLUENCE_SERVER")
+	}
+
+	credsPath := filepath.Join(t.TempDir(), "creds.sh")
+	os.WriteFile(credsPath, []byte("export CONFLUENCE_SERVER=https://wiki.corp.example.com\nexport CONFLUENCE_API_TOKEN=wiki-pat\n"), 0600)
+	server, token, _, err = loadConfluenceCreds(credsPath)
+	if err != nil || server != "https://wiki.corp.example.com" || token != "wiki-pat" {
+		t.Errorf("creds script: %q %q %v", server, token, err)
+	}
+}
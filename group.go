@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// groupByFields are the built-in --group-by values; --field names are accepted too.
+var groupByFields = []string{"assignee", "type", "trending", "status", "priority", "parent"}
+
+// jiraPriorityOrder ranks Jira's default priorities for --group-by priority; others sort after them by name.
+var jiraPriorityOrder = map[string]int{"blocker": 0, "highest": 1, "critical": 2, "high": 3, "medium": 4, "low": 5, "lowest": 6, "minor": 7, "trivial": 8}
+
+// issueGroup is one section of a grouped report.
+type issueGroup struct {
+	Name   string
+	Issues []*IssueData
+}
+
+// groupValue returns the section an issue belongs to for --group-by field.
+func groupValue(issue *IssueData, field string) string {
+	var v string
+	switch field {
+	case "assignee":
+		if v = issue.Assignee; v == "N/A" {
+			v = ""
+		}
+	case "type":
+		v = issue.Type
+	case "trending":
+		v = issue.Trending
+	case "status":
+		v = statusForDisplay(issue)
+	case "priority":
+		if v = issue.Priority; v == "None" {
+			v = ""
+		}
+	case "parent":
+		v = issue.ParentKey
+	default:
+		v = issue.Extra[field]
+	}
+	if v = strings.TrimSpace(v); v == "" {
+		return "no " + field
+	}
+	return v
+}
+
+// resolveGroupBy validates --group-by: a built-in field (case-insensitive) or one of the --field names,
+// returned as configured.
+func resolveGroupBy(field string, extraFields []string) (string, error) {
+	if field == "" {
+		return "", nil
+	}
+	for _, f := range groupByFields {
+		if strings.EqualFold(field, f) {
+			return f, nil
+		}
+	}
+	for _, f := range extraFields {
+		if strings.EqualFold(field, f) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown field %q (use %s, or a --field name)", field, strings.Join(groupByFields, ", "))
+}
+
+// groupRank orders groups: trending worst first, status and priority by their workflow order, everything
+// else by name. The "no <field>" group always comes last.
+func groupRank(g issueGroup, field string) int {
+	if g.Name == "no "+field {
+		return 1 << 30
+	}
+	switch field {
+	case "trending":
+		if r, ok := htmlTrendingOrder[g.Name]; ok {
+			return r
+		}
+		return len(htmlTrendingOrder)
+	case "status":
+		return activeStatusMap.forIssue(g.Issues[0]).Priority
+	case "priority":
+		if r, ok := jiraPriorityOrder[strings.ToLower(g.Name)]; ok {
+			return r
+		}
+		return len(jiraPriorityOrder)
+	}
+	return 0
+}
+
+// groupIssues splits already sorted issues into sections by field (--group-by), keeping their order
+// within each section. Without a field all issues form one unnamed group.
+func groupIssues(issues []*IssueData, field string) []issueGroup {
+	if field == "" {
+		return []issueGroup{{Issues: issues}}
+	}
+	var groups []issueGroup
+	index := map[string]int{}
+	for _, issue := range issues {
+		name := groupValue(issue, field)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, issueGroup{Name: name})
+		}
+		groups[i].Issues = append(groups[i].Issues, issue)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if ri, rj := groupRank(groups[i], field), groupRank(groups[j], field); ri != rj {
+			return ri < rj
+		}
+		return strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name)
+	})
+	return groups
+}
+
+// groupSummary counts a group's issues and their trending, e.g. "3 issues: 1 at risk, 2 on track".
+func groupSummary(issues []*IssueData) string {
+	counts := map[string]int{}
+	present := map[string]bool{}
+	for _, issue := range issues {
+		trending := issue.Trending
+		if trending == "" {
+			trending = "unknown"
+		}
+		counts[trending]++
+		present[trending] = true
+	}
+	var parts []string
+	for _, trending := range sortedTrendings(present) {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[trending], trending))
+	}
+	noun := "issues"
+	if len(issues) == 1 {
+		noun = "issue"
+	}
+	return fmt.Sprintf("%d %s: %s", len(issues), noun, strings.Join(parts, ", "))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func groupTestIssues() []*IssueData {
+	return []*IssueData{
+		{Key: "A-1", URL: "https://jira/browse/A-1", Summary: "One", Status: "in progress", Assignee: "Zoe", Priority: "Low", Trending: "on track", TrendingEmoji: "🟢"},
+		{Key: "A-2", URL: "https://jira/browse/A-2", Summary: "Two", Status: "in progress", Assignee: "N/A", Priority: "Highest", Trending: "off track", TrendingEmoji: "🔴"},
+		{Key: "A-3", URL: "https://jira/browse/A-3", Summary: "Three", Status: "new", Assignee: "ann", Priority: "Low", Trending: "at risk", TrendingEmoji: "🟡"},
+		{Key: "A-4", URL: "https://jira/browse/A-4", Summary: "Four", Status: "new", Assignee: "Zoe", Priority: "None", Trending: "at risk", TrendingEmoji: "🟡"},
+	}
+}
+
+func groupNames(groups []issueGroup) string {
+	var names []string
+	for _, g := range groups {
+		var keys []string
+		for _, issue := range g.Issues {
+			keys = append(keys, issue.Key)
+		}
+		names = append(names, g.Name+"="+strings.Join(keys, ","))
+	}
+	return strings.Join(names, " ")
+}
+
+func TestGroupIssues(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"assignee", "ann=A-3 Zoe=A-1,A-4 no assignee=A-2"},
+		{"trending", "off track=A-2 at risk=A-3,A-4 on track=A-1"},
+		{"priority", "Highest=A-2 Low=A-1,A-3 no priority=A-4"},
+		{"", "=A-1,A-2,A-3,A-4"},
+	}
+	for _, tt := range tests {
+		if got := groupNames(groupIssues(groupTestIssues(), tt.field)); got != tt.want {
+			t.Errorf("group by %q = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestGroupIssues_extraField(t *testing.T) {
+	issues := groupTestIssues()
+	issues[0].Extra = map[string]string{"Sprint": "Sprint 8"}
+	issues[2].Extra = map[string]string{"Sprint": "Sprint 7"}
+	issues[3].Extra = map[string]string{"Sprint": "Sprint 8"}
+	field, err := resolveGroupBy("sprint", []string{"Sprint"})
+	if err != nil || field != "Sprint" {
+		t.Fatalf("resolveGroupBy = %q, %v", field, err)
+	}
+	if got := groupNames(groupIssues(issues, field)); got != "Sprint 7=A-3 Sprint 8=A-1,A-4 no Sprint=A-2" {
+		t.Errorf("group by Sprint = %q", got)
+	}
+	if _, err := resolveGroupBy("sprint", nil); err == nil {
+		t.Error("expected an error for a field that was not requested with --field")
+	}
+}
+
+func TestGroupSummary(t *testing.T) {
+	if got := groupSummary(groupTestIssues()); got != "4 issues: 1 off track, 2 at risk, 1 on track" {
+		t.Errorf("summary = %q", got)
+	}
+	if got := groupSummary(groupTestIssues()[:1]); got != "1 issue: 1 on track" {
+		t.Errorf("summary = %q", got)
+	}
+}
+
+func TestRenderReports_groupBy(t *testing.T) {
+	cfg := &ReportConfig{Title: "Team", GroupBy: "assignee"}
+
+	md := RenderMarkdownReport(groupTestIssues(), cfg)
+	zoe := strings.Index(md, "\n#### Zoe (2 issues: 1 at risk, 1 on track)\n")
+	none := strings.Index(md, "\n#### no assignee (1 issue: 1 off track)\n")
+	if zoe < 0 || none < zoe || strings.Count(md, "| trending | type |") != 3 {
+		t.Errorf("markdown should have a heading and table per assignee:\n%s", md)
+	}
+
+	simple := RenderSimpleReport(groupTestIssues(), cfg)
+	if !strings.HasPrefix(simple, "ann (1 issue: 1 at risk)\n") || !strings.Contains(simple, "\n\nZoe (2 issues: 1 at risk, 1 on track)\n") {
+		t.Errorf("simple groups:\n%s", simple)
+	}
+
+	slack := RenderSlackReport(groupTestIssues(), cfg)
+	if !strings.Contains(slack, "*Zoe* (2 issues: 1 at risk, 1 on track)\n1. 🟢 [One]") || !strings.Contains(slack, "\n2. 🟡 [Four]") {
+		t.Errorf("slack numbering should restart per group:\n%s", slack)
+	}
+
+	html := RenderHTMLReport(groupTestIssues(), cfg)
+	if strings.Count(html, `<table class="report">`) != 3 || !strings.Contains(html, "<h2>no assignee <small>1 issue: 1 off track</small></h2>") {
+		t.Errorf("HTML should have a heading and sortable table per group:\n%s", html)
+	}
+}
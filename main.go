@@ -3,14 +3,45 @@
 // Features:
 //   - Fetch issues by JQL query or direct issue keys.
 //   - Optional --children: load linked/child issues and fold them into trending (default: off).
-//   - Optional --render-children: emit child issues in the report instead of parents (implies --children).
+//   - Optional --depth N: walk the child hierarchy N levels deep so trending rolls up from the leaves.
+//   - Optional --render-children: emit child issues in the report instead of parents (implies --children); child rows name their parent.
+//   - Optional --tree: show each parent with its indented children and grandchildren and a rollup such as
+//     "4/7 done, 1 at risk" (simple, markdown and HTML; implies --children).
+//   - Optional --group-by assignee|type|trending|status|priority|parent: one section per group, with counts.
+//   - Optional --columns and --sort (e.g. key,summary,due and due:asc,priority:desc) for every table report
+//     (markdown, CSV, simple, HTML, Confluence and Jira); resolved custom fields are selectable by display name.
 //   - Derive status from Jira's native status field with emoji decoration.
+//   - Optional ~/.snippets/statuses.json (or --status-map): map workflow statuses to a canonical status, sort priority, emoji and default trending.
+//   - Optional ~/.snippets/trending.json (or --trending-rules): ordered rules on status, type, priority, due date,
+//     comment age and child trending that replace the built-in trending policy.
 //   - Include due date and last update timestamps.
+//   - Optional --field NAME (repeatable): extra Jira fields such as labels, sprint or "Story Points" in JSON, CSV,
+//     --columns and --group-by.
+//   - Optional --changelog: count due-date slips and keep the original due date and status history.
+//   - Each report fetched from Jira is saved as a snapshot in ~/.snippets/snapshots (the newest 100 per query,
+//     up to 180 days); `snippets diff --since-snapshot last|DATE` fetches fresh data and reports
+//     added/removed issues and trending, status, due and comment changes.
+//   - Optional --history N: a trending sparkline (e.g. 🟢🟢🟡🔴) of each issue's last N runs from those snapshots.
+//   - Optional --metrics: lead time, cycle time and time in status percentiles by type and assignee.
 //   - Filter issues by a minimum last-update date.
+//   - Cap issues per JQL search with --max-results (0 = unlimited); truncated runs are flagged in report headers
+//     and warned about on stderr; --json-envelope adds a "truncated" flag to JSON output.
 //   - Emit a combined report for multiple issues or individual reports per issue.
 //   - Default stdout/file output is simple tab-aligned text; use --markdown for the full markdown table.
+//   - Optional --html: a self-contained HTML document (sortable table, badges, collapsible children) for email.
+//   - Optional --jira-wiki: wiki markup tables for Jira Server/Data Center, ADF JSON for Jira Cloud.
+//   - Optional --post-comment KEY: post the report as a comment on KEY (--dry-run prints the payload).
+//   - Optional --confluence: Confluence storage format; --confluence-page-id publishes it to a page.
+//   - Optional --mermaid gantt|graph: a Mermaid Gantt timeline (Created to Due, sectioned by trending) or a
+//     parent/child flowchart coloured by trending, fenced for GitHub and wiki markdown.
+//   - Optional --dot: a Graphviz digraph of parents, children and "blocks" links, highlighting blocking
+//     cycles and issues blocked by off-track work.
+//   - Optional --write-trending: write computed trending into JIRA_TRENDING_STATUS_FIELD for dashboards
+//     and filters, skipping issues that already match (--dry-run previews the changes).
 //   - Output to stdout or append/write to a file (--markdown and --summary emit markdown).
 //   - Supports both Jira Cloud and Jira Server/Data Center.
+//   - Ctrl-C or --timeout cancels in-flight Jira requests and exits non-zero.
+//   - Retries rate-limited (429) and transient 5xx/network failures with backoff, honoring Retry-After.
 //
 // Configuration:
 //
@@ -21,7 +52,11 @@
 //	Optional:
 //	  JIRA_CONCURRENCY - Max parallel API calls (default 8; overridden by --jira-concurrency)
 //	  JIRA_DUE_DATE_FIELD - Custom field display name for due/due date (empty = Jira native Due Date). Overridden by --due-date-field.
-//	  JIRA_TRENDING_STATUS_FIELD - Custom field display name; when set, a non-empty value overrides computed trending for that issue.
+//	  JIRA_TRENDING_STATUS_FIELD - Custom field display name; when set, a non-empty value overrides computed trending for that issue
+//	                               (with --write-trending, computed trending is written to it instead).
+//	  JIRA_CHILD_LINK_TYPES - Comma-separated link types that mark children (default "is parent of"). Overridden by --child-link-types.
+//	  JIRA_HIERARCHY_FIELDS - Comma-separated parent custom fields (default "Epic Link,Parent Link"). Overridden by --hierarchy-fields.
+//	  JIRA_CHILD_ISSUES_OF - true/false: include childIssuesOf(KEY) in child JQL (default true). Overridden by --child-issues-of.
 //
 // Usage:
 //
@@ -36,14 +71,19 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/zachariahcox/snippets/filecache"
@@ -68,13 +108,23 @@ func resolveJiraConcurrency(flagVal int, env string) int {
 // credsFileName is the name of the optional shell script that can export JIRA_* vars.
 const credsFileName = ".snippets/creds.sh"
 
-// loadJiraCustomFieldNames returns JIRA_DUE_DATE_FIELD and JIRA_TRENDING_STATUS_FIELD from the environment,
-// then fills missing values from ~/.snippets/creds.sh when that file exists (same pattern as loadJiraCreds).
-// This runs before cache lookup so cache keys match runs that use creds-only field configuration.
-func loadJiraCustomFieldNames(credsFilePath string) (dueDateField, trendingStatusField string) {
-	dueDateField = strings.TrimSpace(os.Getenv("JIRA_DUE_DATE_FIELD"))
-	trendingStatusField = strings.TrimSpace(os.Getenv("JIRA_TRENDING_STATUS_FIELD"))
+// loadCredsVars returns the named variables from the environment, then fills empty values from the
+// creds shell script (sourced in a subprocess) when it exists. Env vars take precedence.
+// credsFilePath: if non-empty, use this path; otherwise use ~/.snippets/creds.sh. Pass "" in production.
+func loadCredsVars(credsFilePath string, names ...string) map[string]string {
+	vals := make(map[string]string, len(names))
+	missing := false
+	for _, name := range names {
+		vals[name] = os.Getenv(name)
+		if vals[name] == "" {
+			missing = true
+		}
+	}
+	if !missing {
+		return vals
+	}
 
+	// Resolve creds file path
 	var credsPath string
 	if credsFilePath != "" {
 		credsPath = credsFilePath
@@ -87,17 +137,22 @@ func loadJiraCustomFieldNames(credsFilePath string) (dueDateField, trendingStatu
 		}
 	}
 	if credsPath == "" {
-		return dueDateField, trendingStatusField
+		return vals
 	}
 	if _, statErr := os.Stat(credsPath); statErr != nil {
-		return dueDateField, trendingStatusField
+		return vals
+	}
+
+	var script strings.Builder
+	fmt.Fprintf(&script, `. %q 2>/dev/null`, credsPath)
+	for _, name := range names {
+		fmt.Fprintf(&script, `; echo "%s=$%s"`, name, name)
 	}
-	script := fmt.Sprintf(`. %q 2>/dev/null; echo "JIRA_DUE_DATE_FIELD=$JIRA_DUE_DATE_FIELD"; echo "JIRA_TRENDING_STATUS_FIELD=$JIRA_TRENDING_STATUS_FIELD"`, credsPath)
-	cmd := exec.Command("sh", "-c", script)
+	cmd := exec.Command("sh", "-c", script.String())
 	cmd.Env = os.Environ()
 	out, cmdErr := cmd.Output()
 	if cmdErr != nil {
-		return dueDateField, trendingStatusField
+		return vals
 	}
 	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
 		line = strings.TrimSpace(line)
@@ -110,77 +165,78 @@ func loadJiraCustomFieldNames(credsFilePath string) (dueDateField, trendingStatu
 		}
 		key, val := line[:i], line[i+1:]
 		val = strings.Trim(val, "\"")
-		switch key {
-		case "JIRA_DUE_DATE_FIELD":
-			if dueDateField == "" && val != "" {
-				dueDateField = strings.TrimSpace(val)
-			}
-		case "JIRA_TRENDING_STATUS_FIELD":
-			if trendingStatusField == "" && val != "" {
-				trendingStatusField = strings.TrimSpace(val)
-			}
+		if cur, wanted := vals[key]; wanted && cur == "" && val != "" {
+			vals[key] = val
 		}
 	}
-	return dueDateField, trendingStatusField
+	return vals
 }
 
-// loadJiraCreds returns JIRA_SERVER, JIRA_API_TOKEN, and JIRA_EMAIL from the environment
-// and optionally from a creds shell script (sourced in a subprocess). Env vars take precedence.
-// credsFilePath: if non-empty, use this path; otherwise use ~/.snippets/creds.sh. Pass "" in production.
-// Returns an error if JIRA_SERVER or JIRA_API_TOKEN are missing.
-func loadJiraCreds(credsFilePath string) (server, apiToken, email string, err error) {
-	server = os.Getenv("JIRA_SERVER")
-	apiToken = os.Getenv("JIRA_API_TOKEN")
-	email = os.Getenv("JIRA_EMAIL")
+// loadJiraCustomFieldNames returns JIRA_DUE_DATE_FIELD and JIRA_TRENDING_STATUS_FIELD from the environment,
+// then fills missing values from ~/.snippets/creds.sh when that file exists (same pattern as loadJiraCreds).
+// This runs before cache lookup so cache keys match runs that use creds-only field configuration.
+func loadJiraCustomFieldNames(credsFilePath string) (dueDateField, trendingStatusField string) {
+	vals := loadCredsVars(credsFilePath, "JIRA_DUE_DATE_FIELD", "JIRA_TRENDING_STATUS_FIELD")
+	return strings.TrimSpace(vals["JIRA_DUE_DATE_FIELD"]), strings.TrimSpace(vals["JIRA_TRENDING_STATUS_FIELD"])
+}
 
-	// Resolve creds file path
-	var credsPath string
-	if credsFilePath != "" {
-		credsPath = credsFilePath
-	} else {
-		home, homeErr := os.UserHomeDir()
-		if homeErr != nil {
-			credsPath = ""
-		} else {
-			credsPath = filepath.Join(home, credsFileName)
+// childDiscoveryConfig holds the child-discovery settings that may come from flags, env, or creds.sh.
+type childDiscoveryConfig struct {
+	LinkTypes       []string // nil = defaultChildLinkTypes
+	HierarchyFields []string // nil = defaultHierarchyFieldNames
+	NoChildIssuesOf bool
+}
+
+// loadChildDiscoveryConfig reads JIRA_CHILD_LINK_TYPES, JIRA_HIERARCHY_FIELDS (comma-separated; "none" for an
+// empty list) and JIRA_CHILD_ISSUES_OF (true/false) from the environment or creds file. Flag values, when
+// non-empty, take precedence over both.
+func loadChildDiscoveryConfig(credsFilePath, linkTypesFlag, hierarchyFieldsFlag, childIssuesOfFlag string) (childDiscoveryConfig, error) {
+	vals := loadCredsVars(credsFilePath, "JIRA_CHILD_LINK_TYPES", "JIRA_HIERARCHY_FIELDS", "JIRA_CHILD_ISSUES_OF")
+	pick := func(flagVal, name string) string {
+		if strings.TrimSpace(flagVal) != "" {
+			return flagVal
 		}
+		return vals[name]
 	}
-	if credsPath != "" {
-		if _, statErr := os.Stat(credsPath); statErr == nil {
-			script := fmt.Sprintf(`. %q 2>/dev/null; echo "JIRA_SERVER=$JIRA_SERVER"; echo "JIRA_API_TOKEN=$JIRA_API_TOKEN"; echo "JIRA_EMAIL=$JIRA_EMAIL"`, credsPath)
-			cmd := exec.Command("sh", "-c", script)
-			cmd.Env = os.Environ()
-			out, cmdErr := cmd.Output()
-			if cmdErr == nil {
-				for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
-					line = strings.TrimSpace(line)
-					if line == "" {
-						continue
-					}
-					i := strings.Index(line, "=")
-					if i <= 0 {
-						continue
-					}
-					key, val := line[:i], line[i+1:]
-					val = strings.Trim(val, "\"")
-					switch key {
-					case "JIRA_SERVER":
-						if server == "" && val != "" {
-							server = val
-						}
-					case "JIRA_API_TOKEN":
-						if apiToken == "" && val != "" {
-							apiToken = val
-						}
-					case "JIRA_EMAIL":
-						if email == "" && val != "" {
-							email = val
-						}
-					}
-				}
-			}
+	var out childDiscoveryConfig
+	out.LinkTypes = parseNameList(pick(linkTypesFlag, "JIRA_CHILD_LINK_TYPES"))
+	out.HierarchyFields = parseNameList(pick(hierarchyFieldsFlag, "JIRA_HIERARCHY_FIELDS"))
+	if v := strings.TrimSpace(pick(childIssuesOfFlag, "JIRA_CHILD_ISSUES_OF")); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return out, fmt.Errorf("invalid child-issues-of value %q: use true or false", v)
 		}
+		out.NoChildIssuesOf = !b
 	}
+	return out, nil
+}
+
+// parseNameList splits a comma-separated list, trimming blanks. "" returns nil (use defaults);
+// "none" returns an empty, non-nil list (disable).
+func parseNameList(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	out := []string{}
+	if strings.EqualFold(s, "none") {
+		return out
+	}
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// loadJiraCreds returns JIRA_SERVER, JIRA_API_TOKEN, and JIRA_EMAIL from the environment
+// and optionally from a creds shell script (sourced in a subprocess). Env vars take precedence.
+// credsFilePath: if non-empty, use this path; otherwise use ~/.snippets/creds.sh. Pass "" in production.
+// Returns an error if JIRA_SERVER or JIRA_API_TOKEN are missing.
+func loadJiraCreds(credsFilePath string) (server, apiToken, email string, err error) {
+	vals := loadCredsVars(credsFilePath, "JIRA_SERVER", "JIRA_API_TOKEN", "JIRA_EMAIL")
+	server, apiToken, email = vals["JIRA_SERVER"], vals["JIRA_API_TOKEN"], vals["JIRA_EMAIL"]
 
 	// Validate required fields
 	if server == "" {
@@ -208,6 +264,26 @@ type ReportConfig struct {
 	CSVOutput      bool
 	SlackOutput    bool
 	URLOutput      bool
+	HTMLOutput     bool // standalone HTML document (--html)
+	// JiraWikiOutput emits Jira wiki markup, or ADF JSON when JiraServer is Jira Cloud (--jira-wiki).
+	JiraWikiOutput bool
+	// JiraServer is JIRA_SERVER, resolved up front for --jira-wiki (which works from cache without a client).
+	JiraServer string
+	// PostCommentKey, when set, posts the report (wiki markup or ADF, see JiraWikiOutput) as a comment on this issue.
+	PostCommentKey string
+	// DryRun prints what would be sent (JQL, or the --post-comment payload) instead of sending it.
+	DryRun bool
+	// ConfluenceOutput emits Confluence storage-format XHTML (--confluence).
+	ConfluenceOutput bool
+	// ConfluencePageID, when set, publishes the storage-format report to this page instead of printing it.
+	ConfluencePageID string
+	// MermaidOutput selects a fenced Mermaid diagram: "gantt" (Created to Due by trending) or "graph" (parent/child flowchart).
+	MermaidOutput string
+	// DotOutput emits a Graphviz digraph of parents, children and blocking links (--dot; implies LoadIssueLinks).
+	DotOutput bool
+	// WriteTrending writes computed trending into TrendingStatusFieldName instead of printing the report
+	// (--write-trending); the field then no longer overrides computed trending.
+	WriteTrending bool
 
 	// MarkdownOutput selects the full markdown issue table (links, columns). When false and no other
 	// structured format is set, RenderReport uses simple text. SummaryOutput is separate markdown.
@@ -215,15 +291,82 @@ type ReportConfig struct {
 	SummaryOutput   bool
 	JQLQuery        string
 	IncludeChildren bool // fetch child issues and roll them into computeTrending
+	// ChildDepth is how many levels of children to load when IncludeChildren (< 1 means one level).
+	ChildDepth int
+	// ChildLinkTypes are issue link descriptions followed for children; nil means defaultChildLinkTypes.
+	ChildLinkTypes []string
+	// HierarchyFieldNames are custom fields naming an issue's parent; nil means defaultHierarchyFieldNames.
+	HierarchyFieldNames []string
+	// NoChildIssuesOf drops the issue in childIssuesOf(KEY) clause from child JQL.
+	NoChildIssuesOf bool
 
 	// DueDateFieldName is the Jira custom field display name for due/due dates; empty means use the native Due Date (duedate).
 	DueDateFieldName string
 	// TrendingStatusFieldName is the Jira custom field display name for trending; empty means trending is computed from status/dates.
 	TrendingStatusFieldName string
+	// StatusMapDigest identifies the status map file in effect ("" = built-in defaults), so cached trending stays consistent.
+	StatusMapDigest string
+	// TrendingRulesDigest identifies the trending rules file in effect ("" = built-in rules).
+	TrendingRulesDigest string
+	// ExtraFields are additional Jira fields (display names or IDs, e.g. "Story Points", "labels") fetched into
+	// IssueData.Extra and added to JSON, CSV, --columns and --group-by (--field, repeatable).
+	ExtraFields []string
 	// CustomFieldNameToID maps custom field display names to REST field IDs after the client resolves them (filled during fetch).
 	CustomFieldNameToID map[string]string
 
 	RenderChildren bool // render children issues instead of parents
+	// TreeOutput shows each parent followed by its indented descendants and a rollup of its children's
+	// trending in the simple, markdown and HTML reports (--tree; implies IncludeChildren).
+	TreeOutput bool
+	// GroupBy splits the markdown, simple, Slack and HTML reports into one section per value of this
+	// field (one of groupByFields), each with a heading and trending counts (--group-by).
+	GroupBy string
+	// Columns replaces the column set and order of the table reports (--columns; see
+	// reportColumnNames). Custom field display names select IssueData.Extra values.
+	Columns []string
+	// SortKeys re-sort every report after the default status/due/updated order (--sort).
+	SortKeys []SortKey
+
+	// LoadChangelog fetches issue changelogs for due-date slips and status history (--changelog).
+	LoadChangelog bool
+	// LoadIssueLinks requests issuelinks with each search to fill Blocks and BlockedBy (implied by --dot).
+	LoadIssueLinks bool
+	// DiffBaseline, when set, renders what changed since this snapshot instead of the issue list (snippets diff).
+	DiffBaseline *snapshotFile
+	// HistoryRuns adds a trending sparkline of the last N runs (from snapshots) to markdown and simple output (--history).
+	HistoryRuns int
+	// trendingHistory holds each issue's past trending values, oldest first (see withTrendingHistory).
+	trendingHistory map[string][]string
+	// MetricsOutput renders lead time, cycle time and time in status instead of the issue list (--metrics; implies LoadChangelog).
+	MetricsOutput bool
+
+	// MaxResults caps issues per JQL search (parent query and each child query); < 1 means unlimited.
+	MaxResults int
+	// Truncated is set during fetch (and restored from cache) when any search hit MaxResults.
+	Truncated bool
+	// JSONEnvelope wraps --json output in an object with the Truncated flag, so consumers can detect
+	// partial results (--json-envelope); without it JSON is a plain array.
+	JSONEnvelope bool
+}
+
+// stringListFlag collects a repeatable string flag (--field).
+type stringListFlag []string
+
+func (f *stringListFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringListFlag) Set(v string) error {
+	if v = strings.TrimSpace(v); v != "" && !slices.Contains(*f, v) {
+		*f = append(*f, v)
+	}
+	return nil
+}
+
+// childDepth returns the number of child levels to load: 0 without IncludeChildren, else ChildDepth (at least 1).
+func (c *ReportConfig) childDepth() int {
+	if c == nil || !c.IncludeChildren {
+		return 0
+	}
+	return max(c.ChildDepth, 1)
 }
 
 func (c *ReportConfig) String() string {
@@ -238,11 +381,16 @@ func (c *ReportConfig) String() string {
 	if c.NoCommentAfter != nil {
 		noComment = c.NoCommentAfter.Format("2006-01-02")
 	}
-	return fmt.Sprintf("title=%q jql=%q since=%q noCommentAfter=%q out=%q json=%t csv=%t slack=%t url=%t markdown=%t summary=%t children=%t renderChildren=%t dueField=%q trendField=%q fieldIDs=%d",
+	diffSince := ""
+	if c.DiffBaseline != nil {
+		diffSince = c.DiffBaseline.TakenAt.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("title=%q jql=%q since=%q noCommentAfter=%q out=%q json=%t jsonEnvelope=%t csv=%t slack=%t url=%t html=%t jiraWiki=%t jiraServer=%q postComment=%q dryRun=%t confluence=%t confluencePage=%q mermaid=%q dot=%t writeTrending=%t markdown=%t summary=%t children=%t depth=%d links=%q hierarchy=%q childIssuesOf=%t renderChildren=%t tree=%t groupBy=%q columns=%q sort=%v changelog=%t issueLinks=%t metrics=%t diffSince=%q history=%d dueField=%q trendField=%q fields=%q statusMap=%q trendingRules=%q fieldIDs=%d maxResults=%d",
 		c.Title, c.JQLQuery, since, noComment, c.OutputFile,
-		c.JSONOutput, c.CSVOutput, c.SlackOutput, c.URLOutput,
-		c.MarkdownOutput, c.SummaryOutput, c.IncludeChildren, c.RenderChildren,
-		c.DueDateFieldName, c.TrendingStatusFieldName, len(c.CustomFieldNameToID))
+		c.JSONOutput, c.JSONEnvelope, c.CSVOutput, c.SlackOutput, c.URLOutput, c.HTMLOutput, c.JiraWikiOutput, c.JiraServer, c.PostCommentKey, c.DryRun, c.ConfluenceOutput, c.ConfluencePageID, c.MermaidOutput, c.DotOutput, c.WriteTrending,
+		c.MarkdownOutput, c.SummaryOutput, c.IncludeChildren, c.childDepth(),
+		c.ChildLinkTypes, c.HierarchyFieldNames, !c.NoChildIssuesOf, c.RenderChildren, c.TreeOutput, c.GroupBy, c.Columns, c.SortKeys, c.LoadChangelog, c.LoadIssueLinks, c.MetricsOutput, diffSince, c.HistoryRuns,
+		c.DueDateFieldName, c.TrendingStatusFieldName, c.ExtraFields, c.StatusMapDigest, c.TrendingRulesDigest, len(c.CustomFieldNameToID), c.MaxResults)
 }
 
 // ParseSince parses --since: YYYY-MM-DD or numeric days ago (e.g. 14 = now - 14 days).
@@ -323,11 +471,11 @@ func DaysFromNow(dateStr string) (int, bool) {
 	return days, true
 }
 
-// FetchReportIssues generates a report of issues. It tries the cache first; on hit it returns
-// cached data (client may be nil for cache-only lookup). On cache miss with client == nil it
+// FetchReportIssues generates a report of issues. It tries the cache first (except for diff, which always
+// fetches); on hit it returns cached data (client may be nil for cache-only lookup). On cache miss with client == nil it
 // returns ErrCacheMiss. On cache miss with client != nil it fetches from Jira, writes the
-// cache, and returns the result.
-func FetchReportIssues(client *JiraClient, issueKeys []string, cfg *ReportConfig) ([]*IssueData, error) {
+// cache, and returns the result. Cancelling ctx aborts in-flight Jira calls and returns ctx.Err().
+func FetchReportIssues(ctx context.Context, client *JiraClient, issueKeys []string, cfg *ReportConfig) ([]*IssueData, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("cfg is nil")
 	}
@@ -336,14 +484,21 @@ func FetchReportIssues(client *JiraClient, issueKeys []string, cfg *ReportConfig
 	key := CacheKey(cfg, issueKeys)
 	if err := reportCache.EnsureDir(); err != nil {
 		logWarning("Cache dir unavailable: %v", err)
+	} else if cfg.DiffBaseline != nil {
+		// A cache hit is the data behind the newest snapshot, so diff would report no changes.
+		logDebug("Skipping the report cache for diff")
 	} else {
 		// Check cache first without pruning (pruning does ReadDir+Stat on every file and can be slow).
 		path, err := reportCache.Path(key)
 		if err == nil && filecache.Valid(path, reportCacheTTL) {
-			parentIssues, err := readIssueCache(path)
+			ent, err := readIssueCacheFile(path)
 			if err == nil {
 				logInfo("Using cached results at %s.", path)
-				return parentIssues, nil
+				cfg.Truncated = ent.Truncated
+				if ent.Truncated {
+					logWarning("Cached results were truncated at --max-results %d", cfg.MaxResults)
+				}
+				return ent.ParentIssues, nil
 			}
 			logDebug("Cache read failed: %v", err)
 		}
@@ -354,6 +509,8 @@ func FetchReportIssues(client *JiraClient, issueKeys []string, cfg *ReportConfig
 	}
 
 	client.prepareFieldResolution(cfg)
+	client.MaxResults = cfg.MaxResults
+	client.truncated.Store(false)
 
 	// Prune only when we're about to fetch (and possibly write); avoids slow ReadDir+Stat on cache-hit path.
 	_ = reportCache.Prune(reportCacheTTL)
@@ -361,7 +518,7 @@ func FetchReportIssues(client *JiraClient, issueKeys []string, cfg *ReportConfig
 	// load raw issue data
 	var parentIssues []*IssueData
 	if cfg.JQLQuery != "" {
-		issues, err := client.FetchIssuesFromQuery(cfg.JQLQuery)
+		issues, err := client.FetchIssuesFromQuery(ctx, cfg.JQLQuery)
 		if err != nil {
 			logError("JQL query failed: %v", err)
 			return nil, err
@@ -374,7 +531,7 @@ func FetchReportIssues(client *JiraClient, issueKeys []string, cfg *ReportConfig
 			issueKeys[i] = issue.Key
 		}
 	} else {
-		issues, err := client.FetchIssuesByKeys(issueKeys)
+		issues, err := client.FetchIssuesByKeys(ctx, issueKeys)
 		if err != nil {
 			logError("Failed to fetch issues: %v", err)
 			return nil, err
@@ -383,30 +540,130 @@ func FetchReportIssues(client *JiraClient, issueKeys []string, cfg *ReportConfig
 	}
 
 	if cfg.IncludeChildren {
-		client.loadChildren(parentIssues)
+		if err := client.loadChildren(ctx, parentIssues, cfg.childDepth()); err != nil {
+			return nil, err
+		}
 	}
 
 	// load comments
-	client.loadComments(parentIssues)
+	if err := client.loadComments(ctx, parentIssues); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logWarning("Failed to load comments: %v", err)
+	}
+
+	// load changelogs (due-date slips, status history) before trending rules look at them
+	if cfg.LoadChangelog {
+		if err := client.loadChangelogs(ctx, parentIssues); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			logWarning("Failed to load changelogs: %v", err)
+		}
+	}
 
 	// compute trending
 	for _, issue := range parentIssues {
 		computeTrending(issue, cfg.IncludeChildren)
 	}
 
+	cfg.Truncated = client.truncated.Load()
+
 	// Write cache for next run
 	if path, err := reportCache.Path(key); err == nil {
-		if wErr := writeIssueCache(path, parentIssues); wErr != nil {
+		if wErr := writeIssueCacheFile(path, issueCacheFile{ParentIssues: parentIssues, Truncated: cfg.Truncated}); wErr != nil {
 			logWarning("Failed to write cache: %v", wErr)
 		} else {
 			logDebug("Cached results to %s", path)
 		}
 	}
 
+	// Keep a timestamped snapshot for `snippets diff` and --history (kept much longer than the cache)
+	if path, err := saveSnapshot(cfg, issueKeys, parentIssues, time.Now()); err != nil {
+		logWarning("Failed to write snapshot: %v", err)
+	} else {
+		logDebug("Saved snapshot to %s", path)
+	}
+	if err := pruneSnapshots(time.Now()); err != nil {
+		logWarning("Failed to prune snapshots: %v", err)
+	}
+
 	return parentIssues, nil
 }
 
+// renderDryRun describes the JQL a run would execute without searching: the parent query and, when
+// children are loaded, the child JQL for each given issue key (or a <KEY> template for JQL runs).
+// Call after client.prepareFieldResolution and ensureCustomFieldsLoaded so hierarchy fields resolve.
+func renderDryRun(client *JiraClient, issueKeys []string, cfg *ReportConfig) string {
+	var b strings.Builder
+	if cfg.JQLQuery != "" {
+		fmt.Fprintf(&b, "parent JQL: %s\n", cfg.JQLQuery)
+	} else {
+		fmt.Fprintf(&b, "parent JQL: key in (%s)\n", strings.Join(issueKeys, ","))
+	}
+	if !cfg.IncludeChildren {
+		b.WriteString("children: not loaded (use --children or --depth)\n")
+		return strings.TrimRight(b.String(), "\n")
+	}
+	fmt.Fprintf(&b, "child depth: %d\n", cfg.childDepth())
+	for _, name := range client.hierarchyFields() {
+		if id := client.customFieldNameToID[name]; id != "" {
+			fmt.Fprintf(&b, "hierarchy field %q: %s\n", name, id)
+		} else {
+			fmt.Fprintf(&b, "hierarchy field %q: not found on this Jira instance (skipped)\n", name)
+		}
+	}
+	keys := issueKeys
+	if cfg.JQLQuery != "" || len(keys) == 0 {
+		keys = []string{"<KEY>"}
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&b, "child JQL for %s: %s\n", key, client.childrenJQL(key))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// outputReport renders the report, or posts it as a Jira comment (cfg.PostCommentKey, needs client),
+// writes trending back to Jira (cfg.WriteTrending, needs client) or publishes it to Confluence
+// (cfg.ConfluencePageID). main rejects setting more than one of these.
+func outputReport(ctx context.Context, client *JiraClient, parentIssues []*IssueData, cfg *ReportConfig) error {
+	if cfg.PostCommentKey != "" {
+		return postReportComment(ctx, client, parentIssues, cfg)
+	}
+	if cfg.WriteTrending {
+		return writeTrendingField(ctx, client, parentIssues, cfg)
+	}
+	if cfg.ConfluencePageID != "" {
+		return publishConfluenceReport(ctx, parentIssues, cfg)
+	}
+	RenderReport(parentIssues, cfg)
+	return nil
+}
+
+// fetchErrorExitCode logs a fetch failure and returns the process exit status:
+// 130 (128+SIGINT) when the run was interrupted, 1 for timeouts and other errors.
+func fetchErrorExitCode(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		logError("Interrupted; cancelled in-flight Jira requests")
+		return 130
+	case errors.Is(err, context.DeadlineExceeded):
+		logError("Timed out before the report finished (see --timeout)")
+		return 1
+	default:
+		logError("%v", err)
+		return 1
+	}
+}
+
 func main() {
+	// `snippets diff [options] <issue_keys...>` takes the usual options plus --since-snapshot
+	diffMode := len(os.Args) > 1 && os.Args[1] == "diff"
+	if diffMode {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	// Define flags
 	jqlQuery := flag.String("jql", "", "JQL query to fetch issues (alternative to specifying keys)")
 	sinceStr := flag.String("since", "", "Only include issues updated on or after: YYYY-MM-DD, or N (days ago, e.g. 14)")
@@ -421,21 +678,53 @@ func main() {
 	verbose := flag.Bool("verbose", false, "Enable verbose debug logging")
 	verboseShort := flag.Bool("v", false, "Enable verbose debug logging (short)")
 	jsonOutput := flag.Bool("json", false, "Output in JSON format")
+	jsonEnvelope := flag.Bool("json-envelope", false, `Wrap --json output as {"truncated":..., "max_results":..., "issues":[...]} so partial results are detectable`)
 	csvOutput := flag.Bool("csv", false, "Output in CSV format ('cat separated value': 🐱)")
 	slackOutput := flag.Bool("slack", false, "Output as Slack-formatted numbered list")
 	urlOutput := flag.Bool("url", false, "Output a single Jira issues URL with filtered keys as JQL")
+	htmlOutput := flag.Bool("html", false, "Output a standalone HTML document (inline CSS, sortable table, collapsible children) for email")
+	jiraWikiOutput := flag.Bool("jira-wiki", false, "Output for Jira descriptions/comments: wiki markup on Jira Server/Data Center, ADF JSON on Jira Cloud")
+	postComment := flag.String("post-comment", "", "Post the report as a comment on this issue (wiki markup on Server/Data Center, ADF on Cloud); with --dry-run, print the payload")
+	confluenceOutput := flag.Bool("confluence", false, "Output Confluence storage-format XHTML (status macros for trending, Jira macros for keys)")
+	confluencePageID := flag.String("confluence-page-id", "", "Publish the --confluence report to this Confluence page ID, bumping its version (see CONFLUENCE_* env vars)")
+	writeTrending := flag.Bool("write-trending", false, "Write computed trending into JIRA_TRENDING_STATUS_FIELD on each issue whose value differs, then print a summary; with --dry-run, preview the changes")
+	mermaidOutput := flag.String("mermaid", "", "Output a Mermaid diagram in a markdown code fence: gantt (Created to Due, sectioned by trending) or graph (parent/child flowchart)")
+	dotOutput := flag.Bool("dot", false, "Output a Graphviz DOT digraph of parents, children and blocks links (fetches issue links), coloured by trending")
 	markdownOutput := flag.Bool("markdown", false, "Output full markdown report (table with issue links)")
 	summaryOutput := flag.Bool("summary", false, "Output markdown: counts and percents by status (filtered list)")
 	children := flag.Bool("children", false, "Fetch child/linked issues and use them when computing trending")
+	depth := flag.Int("depth", 0, "Load children N levels deep, e.g. initiative > epic > story > sub-task (implies --children; --children alone = 1)")
 	clearCache := flag.Bool("clear-cache", false, "Clear the cache at ~/.snippets/cache and exit")
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	jiraConcurrency := flag.Int("jira-concurrency", 0, "Max parallel Jira API requests (0=use JIRA_CONCURRENCY env or 8)")
+	timeout := flag.Duration("timeout", 0, "Abort the run (and cancel in-flight Jira requests) after this long, e.g. 2m (0=no limit)")
+	retryBudget := flag.Int("retry-budget", 0, "Max total retries of rate-limited or failed Jira requests per run (0=default 50)")
 	dueDateFieldFlag := flag.String("due-date-field", "", "Jira custom field display name for due/due date (overrides JIRA_DUE_DATE_FIELD; empty = native Due Date)")
 	renderChildrenFlag := flag.Bool("render-children", false, "Render child issues instead of parents")
+	groupBy := flag.String("group-by", "", "Split markdown, simple, Slack and HTML output into sections with counts: "+strings.Join(groupByFields, "|"))
+	columns := flag.String("columns", "", "Comma-separated columns for table output (markdown, CSV, simple, HTML, Confluence, Jira), e.g. key,summary,assignee,due,trending (or custom field names)")
+	sortFlag := flag.String("sort", "", "Comma-separated sort keys with optional direction, e.g. due:asc,priority:desc (default: status, due date, updated)")
+	var extraFields stringListFlag
+	flag.Var(&extraFields, "field", `Extra Jira field to fetch by display name or ID, e.g. "Story Points" or labels (repeatable; adds JSON/CSV values, --columns and --group-by)`)
+	treeFlag := flag.Bool("tree", false, `Show each parent with its indented children (and deeper levels with --depth) and a rollup like "4/7 done, 1 at risk" (simple, markdown, HTML; implies --children)`)
+	childLinkTypes := flag.String("child-link-types", "", `Comma-separated issue link types that mark children, or "none" (default "is parent of"; env JIRA_CHILD_LINK_TYPES)`)
+	hierarchyFields := flag.String("hierarchy-fields", "", `Comma-separated parent custom fields, or "none" (default "Epic Link,Parent Link"; env JIRA_HIERARCHY_FIELDS)`)
+	childIssuesOf := flag.String("child-issues-of", "", "Include 'issue in childIssuesOf(KEY)' in child JQL: true or false (default true; env JIRA_CHILD_ISSUES_OF)")
+	statusMapPath := flag.String("status-map", "", "JSON file mapping Jira statuses to canonical status, priority, emoji and trending (default ~/.snippets/statuses.json if present)")
+	changelog := flag.Bool("changelog", false, "Load issue changelogs to report due-date slips (due_slips, original_due) and status history")
+	metrics := flag.Bool("metrics", false, "Output lead time, cycle time and time in status (p50/p85/p95) by type and assignee; markdown, or JSON with --json (implies --changelog)")
+	trendingRulesPath := flag.String("trending-rules", "", "JSON file of ordered trending rules replacing the built-in policy (default ~/.snippets/trending.json if present)")
+	historyRuns := flag.Int("history", 0, "Show each issue's trending over the last N runs (from saved snapshots) in markdown and simple output, e.g. 🟢🟢🟡🔴 (0=off)")
+	sinceSnapshot := flag.String("since-snapshot", "", "With diff: compare against the last snapshot, or the newest one taken on or before YYYY-MM-DD or N days ago (default last)")
+	dryRun := flag.Bool("dry-run", false, "Print the JQL that would run (parent query and child JQL) and exit without searching; with --post-comment or --write-trending, fetch and print what would be written")
+	maxResults := flag.Int("max-results", 1000, "Max issues per JQL search, for the query and each parent's children (0=unlimited)")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: snippets [options] <issue_keys...>
+       snippets diff [--since-snapshot last|YYYY-MM-DD|N] [options] <issue_keys...>
 
-Generate a status report for Jira issues (and optional subtasks/linked issues)
+Generate a status report for Jira issues (and optional subtasks/linked issues).
+diff reports added/removed issues and trending, status, due-date and comment changes
+since a saved snapshot (markdown, --slack or --json).
 
 Options:
 `)
@@ -448,12 +737,27 @@ Environment variables:
   JIRA_CONCURRENCY - Optional max parallel API calls (default 8; overridden by --jira-concurrency)
   JIRA_DUE_DATE_FIELD - Optional custom field display name for due date (empty = native Due Date)
   JIRA_TRENDING_STATUS_FIELD - Optional custom field; when set, non-empty values override computed trending
+                               (--write-trending writes computed trending to it instead)
+  JIRA_CHILD_LINK_TYPES - Optional comma-separated link types for child discovery (default "is parent of")
+  JIRA_HIERARCHY_FIELDS - Optional comma-separated parent custom fields (default "Epic Link,Parent Link")
+  JIRA_CHILD_ISSUES_OF - Optional true/false: include childIssuesOf(KEY) in child JQL (default true)
+  CONFLUENCE_SERVER - Confluence base URL for --confluence-page-id (Cloud default: JIRA_SERVER/wiki)
+  CONFLUENCE_API_TOKEN, CONFLUENCE_EMAIL - Confluence credentials (default: the JIRA_* values)
 
 Examples:
   snippets PROJECT-123 PROJECT-456
   snippets --markdown --jql "project = MYPROJ AND status != Done"
   snippets --children --since 2026-01-01 PROJECT-123
+  snippets --depth 3 --markdown INITIATIVE-1
+  snippets --tree --depth 2 INITIATIVE-1
+  snippets --markdown --group-by assignee --jql "project = MYPROJ AND status != Done"
+  snippets --csv --columns key,summary,assignee,due --sort due:asc,priority:desc PROJECT-123
+  snippets --json --field labels --field "Story Points" --jql "project = MYPROJ"
+  snippets --json --json-envelope --max-results 500 --jql "project = MYPROJ"
   snippets --markdown --title "Weekly Status" PROJECT-123 PROJECT-456
+  snippets --mermaid graph --depth 2 -o status.md INITIATIVE-1
+  snippets --dot --children INITIATIVE-1 | dot -Tsvg -o plan.svg
+  snippets diff --since-snapshot 7 --jql "project = MYPROJ"
 `)
 	}
 
@@ -569,29 +873,153 @@ Examples:
 		dueDateFieldName = dueFromEnv
 	}
 
+	discovery, err := loadChildDiscoveryConfig("", *childLinkTypes, *hierarchyFields, *childIssuesOf)
+	if err != nil {
+		logError("%v", err)
+		os.Exit(1)
+	}
+
+	statuses, statusMapDigest, err := loadStatusMap(*statusMapPath)
+	if err != nil {
+		logError("%v", err)
+		os.Exit(1)
+	}
+	activeStatusMap = statuses
+
+	rules, trendingRulesDigest, err := loadTrendingRules(*trendingRulesPath)
+	if err != nil {
+		logError("%v", err)
+		os.Exit(1)
+	}
+	activeTrendingRules = rules
+	if usesDueSlips(rules) && !*changelog {
+		logInfo("Trending rules use due_slips; loading changelogs")
+		*changelog = true
+	}
+
+	if *metrics && !*changelog {
+		*changelog = true
+	}
+
 	cfg := &ReportConfig{
 		Title:                   *title,
 		UpdatedAfter:            since,
 		NoCommentAfter:          noCommentAfter,
 		OutputFile:              *outputFile,
 		JSONOutput:              *jsonOutput,
+		JSONEnvelope:            *jsonEnvelope,
 		CSVOutput:               *csvOutput,
 		SlackOutput:             *slackOutput,
 		URLOutput:               *urlOutput,
+		HTMLOutput:              *htmlOutput,
+		JiraWikiOutput:          *jiraWikiOutput,
+		PostCommentKey:          strings.TrimSpace(*postComment),
+		DryRun:                  *dryRun,
+		ConfluenceOutput:        *confluenceOutput || *confluencePageID != "",
+		ConfluencePageID:        strings.TrimSpace(*confluencePageID),
+		WriteTrending:           *writeTrending,
+		MermaidOutput:           strings.ToLower(strings.TrimSpace(*mermaidOutput)),
+		DotOutput:               *dotOutput,
+		LoadIssueLinks:          *dotOutput,
 		MarkdownOutput:          *markdownOutput,
 		SummaryOutput:           *summaryOutput,
 		JQLQuery:                *jqlQuery,
-		IncludeChildren:         *children || *renderChildrenFlag,
+		IncludeChildren:         *children || *renderChildrenFlag || *treeFlag || *depth > 0,
+		ChildDepth:              *depth,
 		RenderChildren:          *renderChildrenFlag,
+		TreeOutput:              *treeFlag,
+		GroupBy:                 strings.TrimSpace(*groupBy),
+		ExtraFields:             extraFields,
 		DueDateFieldName:        dueDateFieldName,
 		TrendingStatusFieldName: trendFromEnv,
+		MaxResults:              *maxResults,
+		ChildLinkTypes:          discovery.LinkTypes,
+		HierarchyFieldNames:     discovery.HierarchyFields,
+		NoChildIssuesOf:         discovery.NoChildIssuesOf,
+		StatusMapDigest:         statusMapDigest,
+		TrendingRulesDigest:     trendingRulesDigest,
+		LoadChangelog:           *changelog,
+		MetricsOutput:           *metrics,
+		HistoryRuns:             *historyRuns,
 	}
 
-	// Try cache first when not in individual mode (skip Jira entirely on hit)
-	if !*individual {
-		parentIssues, err := FetchReportIssues(nil, issueKeys, cfg)
+	if cfg.Columns, err = parseColumns(*columns, cfg.customFieldNames()); err != nil {
+		logError("--columns: %v", err)
+		os.Exit(1)
+	}
+	if cfg.SortKeys, err = parseSortKeys(*sortFlag, cfg.customFieldNames()); err != nil {
+		logError("--sort: %v", err)
+		os.Exit(1)
+	}
+	if cfg.GroupBy, err = resolveGroupBy(cfg.GroupBy, cfg.ExtraFields); err != nil {
+		logError("--group-by: %v", err)
+		os.Exit(1)
+	}
+	if cfg.TreeOutput && cfg.RenderChildren {
+		logError("--tree and --render-children are mutually exclusive")
+		os.Exit(1)
+	}
+	if cfg.MermaidOutput != "" && cfg.MermaidOutput != "gantt" && cfg.MermaidOutput != "graph" {
+		logError("--mermaid must be gantt or graph, got %q", *mermaidOutput)
+		os.Exit(1)
+	}
+	if cfg.JiraWikiOutput {
+		cfg.JiraServer = loadCredsVars("", "JIRA_SERVER")["JIRA_SERVER"]
+	}
+	if *sinceSnapshot != "" && !diffMode {
+		logError("--since-snapshot is only valid with the diff subcommand")
+		os.Exit(1)
+	}
+	if diffMode {
+		if *individual {
+			logError("diff does not support --individual")
+			os.Exit(1)
+		}
+		base, err := findSnapshot(cfg, issueKeys, *sinceSnapshot, time.Now().UTC())
+		if err != nil {
+			logError("%v", err)
+			os.Exit(1)
+		}
+		logInfo("Diffing against snapshot taken %s", base.TakenAt.Format(time.RFC3339))
+		cfg.DiffBaseline = &base
+	}
+
+	// Cancel in-flight Jira calls on Ctrl-C/SIGTERM or once --timeout elapses.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	actions := 0
+	for _, set := range []bool{cfg.PostCommentKey != "", cfg.WriteTrending, cfg.ConfluencePageID != ""} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		logError("--post-comment, --write-trending and --confluence-page-id cannot be combined; run them separately")
+		os.Exit(1)
+	}
+	if (cfg.ConfluencePageID != "" || cfg.PostCommentKey != "" || cfg.WriteTrending) && *individual {
+		logError("--confluence-page-id, --post-comment and --write-trending do not support --individual")
+		os.Exit(1)
+	}
+	if cfg.WriteTrending && cfg.TrendingStatusFieldName == "" {
+		logError("--write-trending needs JIRA_TRENDING_STATUS_FIELD (the custom field to write)")
+		os.Exit(1)
+	}
+
+	// Try cache first when not in individual mode (skip Jira entirely on hit); posting a comment or
+	// writing trending needs the client, and diff needs fresh data
+	if !*individual && !*dryRun && cfg.PostCommentKey == "" && !cfg.WriteTrending && cfg.DiffBaseline == nil {
+		parentIssues, err := FetchReportIssues(ctx, nil, issueKeys, cfg)
 		if err == nil {
-			RenderReport(parentIssues, cfg)
+			if err := outputReport(ctx, nil, parentIssues, withTrendingHistory(cfg, issueKeys, parentIssues)); err != nil {
+				os.Exit(fetchErrorExitCode(err))
+			}
 			os.Exit(0)
 		}
 		if err != ErrCacheMiss {
@@ -610,31 +1038,42 @@ Examples:
 		logDebug("JIRA_EMAIL is not set. Set the env var or export it from ~/.snippets/creds.sh for Cloud.")
 	}
 	var client *JiraClient
-	client, err = NewJiraClient(server, apiToken, email)
+	client, err = NewJiraClient(ctx, server, apiToken, email)
 	if err != nil {
+		if ctx.Err() != nil {
+			os.Exit(fetchErrorExitCode(ctx.Err()))
+		}
 		logError("%v", err)
 		os.Exit(1)
 	}
 	client.MaxConcurrent = resolveJiraConcurrency(*jiraConcurrency, os.Getenv("JIRA_CONCURRENCY"))
 	logDebug("Jira max concurrent requests: %d", client.concurrencyCap())
+	client.RetryBudget = *retryBudget
+
+	if *dryRun && cfg.PostCommentKey == "" && !cfg.WriteTrending {
+		client.prepareFieldResolution(cfg)
+		client.ensureCustomFieldsLoaded(ctx)
+		fmt.Println(renderDryRun(client, issueKeys, cfg))
+		os.Exit(0)
+	}
 
 	// Fetch issues and render report
 	// if there are multiple "parents", render multiple reports.
 	if *individual {
 		for _, issueKey := range issueKeys {
-			parentIssues, err := FetchReportIssues(client, []string{issueKey}, cfg)
+			parentIssues, err := FetchReportIssues(ctx, client, []string{issueKey}, cfg)
 			if err != nil {
-				logError("%v", err)
-				os.Exit(1)
+				os.Exit(fetchErrorExitCode(err))
 			}
-			RenderReport(parentIssues, cfg)
+			RenderReport(parentIssues, withTrendingHistory(cfg, []string{issueKey}, parentIssues))
 		}
 	} else {
-		parentIssues, err := FetchReportIssues(client, issueKeys, cfg)
+		parentIssues, err := FetchReportIssues(ctx, client, issueKeys, cfg)
 		if err != nil {
-			logError("%v", err)
-			os.Exit(1)
+			os.Exit(fetchErrorExitCode(err))
+		}
+		if err := outputReport(ctx, client, parentIssues, withTrendingHistory(cfg, issueKeys, parentIssues)); err != nil {
+			os.Exit(fetchErrorExitCode(err))
 		}
-		RenderReport(parentIssues, cfg)
 	}
 }
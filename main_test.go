@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -74,6 +77,80 @@ export JIRA_TRENDING_STATUS_FIELD="Health"
 	}
 }
 
+func TestLoadChildDiscoveryConfig(t *testing.T) {
+	dir := t.TempDir()
+	credsPath := filepath.Join(dir, "creds.sh")
+	script := `export JIRA_CHILD_LINK_TYPES="implements, is parent of"
+export JIRA_HIERARCHY_FIELDS="none"
+export JIRA_CHILD_ISSUES_OF="false"
+`
+	if err := os.WriteFile(credsPath, []byte(script), 0700); err != nil {
+		t.Fatalf("write creds: %v", err)
+	}
+	t.Setenv("JIRA_CHILD_LINK_TYPES", "")
+	t.Setenv("JIRA_HIERARCHY_FIELDS", "")
+	t.Setenv("JIRA_CHILD_ISSUES_OF", "")
+
+	got, err := loadChildDiscoveryConfig(credsPath, "", "", "")
+	if err != nil {
+		t.Fatalf("loadChildDiscoveryConfig: %v", err)
+	}
+	if !reflect.DeepEqual(got.LinkTypes, []string{"implements", "is parent of"}) {
+		t.Errorf("LinkTypes = %q", got.LinkTypes)
+	}
+	if got.HierarchyFields == nil || len(got.HierarchyFields) != 0 {
+		t.Errorf("HierarchyFields = %#v, want empty non-nil (disabled)", got.HierarchyFields)
+	}
+	if !got.NoChildIssuesOf {
+		t.Error("NoChildIssuesOf should be set from creds file")
+	}
+
+	// Flags override the creds file.
+	got, err = loadChildDiscoveryConfig(credsPath, "relates to", "Feature Link", "true")
+	if err != nil {
+		t.Fatalf("loadChildDiscoveryConfig: %v", err)
+	}
+	if !reflect.DeepEqual(got.LinkTypes, []string{"relates to"}) || !reflect.DeepEqual(got.HierarchyFields, []string{"Feature Link"}) || got.NoChildIssuesOf {
+		t.Errorf("flags not applied: %+v", got)
+	}
+
+	if _, err := loadChildDiscoveryConfig(credsPath, "", "", "maybe"); err == nil {
+		t.Error("expected error for invalid child-issues-of value")
+	}
+}
+
+func TestParseNameList(t *testing.T) {
+	if got := parseNameList("  "); got != nil {
+		t.Errorf("blank = %#v, want nil", got)
+	}
+	if got := parseNameList("None"); got == nil || len(got) != 0 {
+		t.Errorf("none = %#v, want empty", got)
+	}
+	if got := parseNameList(" a , ,b "); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("list = %q", got)
+	}
+}
+
+func TestRenderDryRun(t *testing.T) {
+	c := &JiraClient{customFieldNameToID: map[string]string{defaultEpicLinkFieldName: "customfield_10001"}, customFieldsLoaded: true}
+	out := renderDryRun(c, []string{"A-1", "B-2"}, &ReportConfig{IncludeChildren: true, ChildDepth: 2})
+	for _, want := range []string{
+		"parent JQL: key in (A-1,B-2)",
+		"child depth: 2",
+		`hierarchy field "Epic Link": customfield_10001`,
+		`hierarchy field "Parent Link": not found`,
+		`child JQL for B-2: parent = B-2 OR issue in childIssuesOf(B-2)`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run missing %q\n%s", want, out)
+		}
+	}
+	out = renderDryRun(c, nil, &ReportConfig{JQLQuery: "project = X"})
+	if !strings.Contains(out, "parent JQL: project = X") || !strings.Contains(out, "children: not loaded") {
+		t.Errorf("dry run without children:\n%s", out)
+	}
+}
+
 func TestLoadJiraCreds_missingRequired(t *testing.T) {
 	credsPath := filepath.Join(t.TempDir(), "creds.sh") // no file, so env only
 
@@ -144,3 +221,15 @@ func TestParseSince(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchErrorExitCode(t *testing.T) {
+	if got := fetchErrorExitCode(context.Canceled); got != 130 {
+		t.Errorf("canceled: got %d, want 130", got)
+	}
+	if got := fetchErrorExitCode(fmt.Errorf("search: %w", context.DeadlineExceeded)); got != 1 {
+		t.Errorf("deadline: got %d, want 1", got)
+	}
+	if got := fetchErrorExitCode(fmt.Errorf("API error: 400")); got != 1 {
+		t.Errorf("other: got %d, want 1", got)
+	}
+}
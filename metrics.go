@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Status classes used by the metrics report, derived from the status map's default trending.
+const (
+	statusClassTodo       = "todo"
+	statusClassInProgress = "in progress"
+	statusClassDone       = "done"
+)
+
+// metricsPercentiles are reported for every distribution.
+var metricsPercentiles = []int{50, 85, 95}
+
+// IssueMetrics are flow metrics for one issue, from its created date and status history (--changelog).
+// Durations are in days; nil means not applicable (e.g. not done yet).
+type IssueMetrics struct {
+	Key           string             `json:"key"`
+	Type          string             `json:"type"`
+	Assignee      string             `json:"assignee"`
+	Status        string             `json:"status"`
+	Done          bool               `json:"done"`
+	LeadTimeDays  *float64           `json:"lead_time_days,omitempty"`  // created -> done
+	CycleTimeDays *float64           `json:"cycle_time_days,omitempty"` // first in progress -> done
+	TimeInStatus  map[string]float64 `json:"time_in_status_days"`       // Jira status name -> days (done statuses excluded)
+}
+
+// Distribution summarizes a set of durations in days.
+type Distribution struct {
+	Count       int                `json:"count"`
+	Percentiles map[string]float64 `json:"percentiles,omitempty"` // "p50", "p85", "p95"
+}
+
+// GroupMetrics aggregates IssueMetrics for all issues, one issue type, or one assignee.
+type GroupMetrics struct {
+	Group                 string                  `json:"group"` // "all", "type: epic", "assignee: Jane Doe"
+	Issues                int                     `json:"issues"`
+	Done                  int                     `json:"done"`
+	DoneWithoutTransition int                     `json:"done_without_transition"` // done, but excluded from lead and cycle time
+	LeadTime              Distribution            `json:"lead_time_days"`
+	CycleTime             Distribution            `json:"cycle_time_days"`
+	TimeInStatus          map[string]Distribution `json:"time_in_status_days"`
+}
+
+// MetricsReport is the --metrics --json output.
+type MetricsReport struct {
+	Issues []IssueMetrics `json:"issues"`
+	Groups []GroupMetrics `json:"groups"`
+}
+
+// statusClassifier maps Jira status names to todo / in progress / done. Names come from activeStatusMap,
+// then from the issues in the report (their current StatusName -> Status), since changelogs carry no
+// status category.
+type statusClassifier map[string]string
+
+func newStatusClassifier(issues []*IssueData) statusClassifier {
+	classOf := func(trending string) string {
+		switch trending {
+		case "done":
+			return statusClassDone
+		case "", "not started", "unknown":
+			return statusClassTodo
+		}
+		return statusClassInProgress
+	}
+	c := make(statusClassifier)
+	for name, info := range activeStatusMap.statuses {
+		c[name] = classOf(info.Trending)
+	}
+	for _, issue := range issues {
+		name := strings.ToLower(strings.TrimSpace(issue.StatusName))
+		if _, ok := c[name]; name == "" || ok {
+			continue
+		}
+		c[name] = classOf(activeStatusMap.forIssue(issue).Trending)
+	}
+	return c
+}
+
+func (c statusClassifier) class(statusName string) string {
+	if cl, ok := c[strings.ToLower(strings.TrimSpace(statusName))]; ok {
+		return cl
+	}
+	return statusClassTodo
+}
+
+// computeIssueMetrics derives lead time, cycle time and time in status from issue.StatusHistory.
+// Without history the issue is treated as having been in its current status since it was created; a done
+// issue without a recorded done transition gets no lead or cycle time, since when it finished is unknown.
+func computeIssueMetrics(issue *IssueData, classify statusClassifier, now time.Time) IssueMetrics {
+	m := IssueMetrics{
+		Key:          issue.Key,
+		Type:         issue.Type,
+		Assignee:     issue.Assignee,
+		Status:       statusForDisplay(issue),
+		TimeInStatus: map[string]float64{},
+	}
+	created, err := ParseJiraDate(issue.Created)
+	if err != nil {
+		return m
+	}
+
+	current := issue.StatusName
+	if len(issue.StatusHistory) > 0 && issue.StatusHistory[0].From != "" {
+		current = issue.StatusHistory[0].From
+	}
+	since := created
+	var started, doneAt time.Time
+	addInterval := func(status string, until time.Time) {
+		if classify.class(status) == statusClassDone || !until.After(since) {
+			return
+		}
+		key := strings.ToLower(strings.TrimSpace(status))
+		m.TimeInStatus[key] += until.Sub(since).Hours() / 24
+	}
+	for _, ch := range issue.StatusHistory {
+		at, err := ParseJiraDate(ch.At)
+		if err != nil {
+			continue
+		}
+		addInterval(current, at)
+		switch classify.class(ch.To) {
+		case statusClassInProgress:
+			if started.IsZero() {
+				started = at
+			}
+			doneAt = time.Time{}
+		case statusClassDone:
+			if doneAt.IsZero() {
+				doneAt = at
+			}
+		default:
+			doneAt = time.Time{} // reopened
+		}
+		current, since = ch.To, at
+	}
+	addInterval(current, now)
+
+	if classify.class(current) == statusClassDone {
+		m.Done = true
+		if doneAt.IsZero() {
+			return m
+		}
+		lead := doneAt.Sub(created).Hours() / 24
+		m.LeadTimeDays = &lead
+		if !started.IsZero() && !started.After(doneAt) {
+			cycle := doneAt.Sub(started).Hours() / 24
+			m.CycleTimeDays = &cycle
+		}
+	}
+	return m
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of sorted values.
+func percentile(sorted []float64, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+func newDistribution(values []float64) Distribution {
+	d := Distribution{Count: len(values)}
+	if len(values) == 0 {
+		return d
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	d.Percentiles = make(map[string]float64, len(metricsPercentiles))
+	for _, p := range metricsPercentiles {
+		d.Percentiles[fmt.Sprintf("p%d", p)] = math.Round(percentile(sorted, p)*10) / 10
+	}
+	return d
+}
+
+func aggregateMetrics(group string, items []IssueMetrics) GroupMetrics {
+	g := GroupMetrics{Group: group, Issues: len(items), TimeInStatus: map[string]Distribution{}}
+	var lead, cycle []float64
+	inStatus := map[string][]float64{}
+	for _, m := range items {
+		if m.Done {
+			g.Done++
+			if m.LeadTimeDays == nil {
+				g.DoneWithoutTransition++
+			}
+		}
+		if m.LeadTimeDays != nil {
+			lead = append(lead, *m.LeadTimeDays)
+		}
+		if m.CycleTimeDays != nil {
+			cycle = append(cycle, *m.CycleTimeDays)
+		}
+		for status, days := range m.TimeInStatus {
+			inStatus[status] = append(inStatus[status], days)
+		}
+	}
+	g.LeadTime, g.CycleTime = newDistribution(lead), newDistribution(cycle)
+	for status, values := range inStatus {
+		g.TimeInStatus[status] = newDistribution(values)
+	}
+	return g
+}
+
+// buildMetricsReport computes metrics for the filtered issues (same filters as other reports),
+// aggregated for all issues, then per type and per assignee.
+func buildMetricsReport(issues []*IssueData, cfg *ReportConfig, now time.Time) MetricsReport {
+	issues = filterAndSortIssues(issues, cfg)
+	classify := newStatusClassifier(issues)
+	report := MetricsReport{Issues: make([]IssueMetrics, 0, len(issues))}
+	byType, byAssignee := map[string][]IssueMetrics{}, map[string][]IssueMetrics{}
+	for _, issue := range issues {
+		m := computeIssueMetrics(issue, classify, now)
+		report.Issues = append(report.Issues, m)
+		byType[m.Type] = append(byType[m.Type], m)
+		byAssignee[m.Assignee] = append(byAssignee[m.Assignee], m)
+	}
+	report.Groups = append(report.Groups, aggregateMetrics("all", report.Issues))
+	for _, grouped := range []struct {
+		label  string
+		groups map[string][]IssueMetrics
+	}{{"type", byType}, {"assignee", byAssignee}} {
+		names := make([]string, 0, len(grouped.groups))
+		for name := range grouped.groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			report.Groups = append(report.Groups, aggregateMetrics(grouped.label+": "+name, grouped.groups[name]))
+		}
+	}
+	return report
+}
+
+// RenderMetricsJSON renders the --metrics report as JSON.
+func RenderMetricsJSON(issues []*IssueData, cfg *ReportConfig) string {
+	data, err := json.MarshalIndent(buildMetricsReport(issues, cfg, time.Now().UTC()), "", "  ")
+	if err != nil {
+		logError("Failed to marshal metrics: %v", err)
+		return "{}"
+	}
+	return string(data)
+}
+
+// RenderMetricsMarkdown renders lead/cycle time percentiles per group and time in status for all issues.
+func RenderMetricsMarkdown(issues []*IssueData, cfg *ReportConfig) string {
+	return renderMetricsMarkdown(buildMetricsReport(issues, cfg, time.Now().UTC()), cfg)
+}
+
+func renderMetricsMarkdown(report MetricsReport, cfg *ReportConfig) string {
+	title := ""
+	if cfg != nil {
+		title = escapeMarkdownInline(cfg.Title)
+	}
+	cell := func(d Distribution, p int) string {
+		if d.Count == 0 {
+			return "—"
+		}
+		return fmt.Sprintf("%.1f", d.Percentiles[fmt.Sprintf("p%d", p)])
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n### %s — metrics @ %s\n\n", title, time.Now().Format(time.RFC3339))
+	all := report.Groups[0]
+	fmt.Fprintf(&b, "* issues: %d (%d done)\n", all.Issues, all.Done)
+	b.WriteString("* lead time = created → done; cycle time = first in progress → done; all values in days\n")
+	if all.DoneWithoutTransition > 0 {
+		fmt.Fprintf(&b, "* %d done issues have no recorded done transition and are excluded from lead and cycle time\n", all.DoneWithoutTransition)
+	}
+	if note := truncationNote(cfg); note != "" {
+		fmt.Fprintf(&b, "* %s\n", note)
+	}
+	b.WriteString("\n| group | issues | done | lead p50 | lead p85 | lead p95 | cycle p50 | cycle p85 | cycle p95 |\n")
+	b.WriteString("|:--|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	for _, g := range report.Groups {
+		fmt.Fprintf(&b, "| %s | %d | %d | %s | %s | %s | %s | %s | %s |\n",
+			escapeMarkdownInline(g.Group), g.Issues, g.Done,
+			cell(g.LeadTime, 50), cell(g.LeadTime, 85), cell(g.LeadTime, 95),
+			cell(g.CycleTime, 50), cell(g.CycleTime, 85), cell(g.CycleTime, 95))
+	}
+
+	statuses := make([]string, 0, len(all.TimeInStatus))
+	for s := range all.TimeInStatus {
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		pi, pj := GetStatusPriority(statuses[i]), GetStatusPriority(statuses[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return statuses[i] < statuses[j]
+	})
+	b.WriteString("\n| time in status | issues | p50 | p85 | p95 |\n")
+	b.WriteString("|:--|---:|---:|---:|---:|\n")
+	for _, s := range statuses {
+		d := all.TimeInStatus[s]
+		fmt.Fprintf(&b, "| %s | %d | %s | %s | %s |\n", escapeMarkdownInline(s), d.Count, cell(d, 50), cell(d, 85), cell(d, 95))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
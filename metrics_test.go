@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeIssueMetrics_leadCycleAndTimeInStatus(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	issue := &IssueData{
+		Key: "A-1", Type: "Story", Assignee: "Ann", Status: "closed", StatusName: "Closed",
+		Created: "2025-01-01T00:00:00.000+0000",
+		StatusHistory: []FieldChange{
+			{At: "2025-01-03T00:00:00.000+0000", From: "New", To: "In Progress"},
+			{At: "2025-01-05T00:00:00.000+0000", From: "In Progress", To: "Blocked"},
+			{At: "2025-01-06T00:00:00.000+0000", From: "Blocked", To: "In Progress"},
+			{At: "2025-01-11T00:00:00.000+0000", From: "In Progress", To: "Closed"},
+		},
+	}
+	m := computeIssueMetrics(issue, newStatusClassifier([]*IssueData{issue}), now)
+	if !m.Done || m.LeadTimeDays == nil || *m.LeadTimeDays != 10 {
+		t.Fatalf("lead time = %v (done=%t), want 10", m.LeadTimeDays, m.Done)
+	}
+	if m.CycleTimeDays == nil || *m.CycleTimeDays != 8 {
+		t.Errorf("cycle time = %v, want 8", m.CycleTimeDays)
+	}
+	want := map[string]float64{"new": 2, "in progress": 7, "blocked": 1}
+	for s, days := range want {
+		if m.TimeInStatus[s] != days {
+			t.Errorf("time in %q = %v, want %v (all: %v)", s, m.TimeInStatus[s], days, m.TimeInStatus)
+		}
+	}
+	if _, ok := m.TimeInStatus["closed"]; ok {
+		t.Error("done statuses should not accumulate time")
+	}
+
+	closed := &IssueData{Key: "A-3", Status: "closed", StatusName: "Closed", Created: "2025-02-01T00:00:00.000+0000"}
+	cm := computeIssueMetrics(closed, newStatusClassifier([]*IssueData{closed}), now)
+	if !cm.Done || cm.LeadTimeDays != nil || cm.CycleTimeDays != nil {
+		t.Errorf("done issue without a done transition should have no lead or cycle time: %+v", cm)
+	}
+	if g := aggregateMetrics("all", []IssueMetrics{m, cm}); g.Done != 2 || g.DoneWithoutTransition != 1 || g.LeadTime.Count != 1 {
+		t.Errorf("aggregate = %+v, want 2 done with 1 excluded from lead time", g)
+	}
+
+	open := &IssueData{Key: "A-2", Status: "in progress", StatusName: "In Progress", Created: "2025-02-27T00:00:00.000+0000"}
+	om := computeIssueMetrics(open, newStatusClassifier([]*IssueData{open}), now)
+	if om.Done || om.LeadTimeDays != nil || om.CycleTimeDays != nil || om.TimeInStatus["in progress"] != 2 {
+		t.Errorf("open issue without history: %+v", om)
+	}
+}
+
+func TestNewStatusClassifier_usesIssueStatusCategory(t *testing.T) {
+	// "Done" is not in the status map, but the issue's statusCategory fallback resolved it.
+	issue := &IssueData{Key: "D-1", Status: "resolved", StatusName: "Done"}
+	c := newStatusClassifier([]*IssueData{issue})
+	if c.class("done") != statusClassDone || c.class("In Progress") != statusClassInProgress || c.class("Whatever") != statusClassTodo {
+		t.Errorf("classes: done=%q in progress=%q whatever=%q", c.class("done"), c.class("In Progress"), c.class("Whatever"))
+	}
+}
+
+func TestPercentileAndDistribution(t *testing.T) {
+	d := newDistribution([]float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10})
+	if d.Count != 10 || d.Percentiles["p50"] != 5 || d.Percentiles["p85"] != 9 || d.Percentiles["p95"] != 10 {
+		t.Errorf("distribution = %+v", d)
+	}
+	if empty := newDistribution(nil); empty.Count != 0 || empty.Percentiles != nil {
+		t.Errorf("empty distribution = %+v", empty)
+	}
+}
+
+func TestBuildMetricsReport_groupsAndFilters(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	done := func(key, typ, assignee, created, closed string) *IssueData {
+		return &IssueData{
+			Key: key, Type: typ, Assignee: assignee, Status: "closed", StatusName: "Closed",
+			Created: created, Updated: closed,
+			StatusHistory: []FieldChange{{At: closed, From: "In Progress", To: "Closed"}},
+		}
+	}
+	issues := []*IssueData{
+		done("A-1", "Story", "Ann", "2025-01-01T00:00:00.000+0000", "2025-01-05T00:00:00.000+0000"),
+		done("A-2", "Bug", "Bob", "2025-01-01T00:00:00.000+0000", "2025-01-03T00:00:00.000+0000"),
+		done("A-3", "Story", "Bob", "2024-01-01T00:00:00.000+0000", "2024-02-01T00:00:00.000+0000"),
+	}
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := buildMetricsReport(issues, &ReportConfig{UpdatedAfter: &since}, now)
+
+	if len(report.Issues) != 2 {
+		t.Fatalf("--since should drop A-3: %+v", report.Issues)
+	}
+	var groups []string
+	for _, g := range report.Groups {
+		groups = append(groups, g.Group)
+	}
+	if got := strings.Join(groups, ","); got != "all,type: Bug,type: Story,assignee: Ann,assignee: Bob" {
+		t.Errorf("groups = %s", got)
+	}
+	if all := report.Groups[0]; all.Done != 2 || all.LeadTime.Percentiles["p50"] != 2 || all.LeadTime.Percentiles["p95"] != 4 {
+		t.Errorf("all = %+v", all)
+	}
+
+	md := renderMetricsMarkdown(report, &ReportConfig{Title: "Flow"})
+	for _, want := range []string{"metrics @", "| all | 2 | 2 | 2.0 | 4.0 | 4.0 | — | — | — |", "| type: Story | 1 | 1 | 4.0 |", "| in progress | 2 |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	var decoded MetricsReport
+	if err := json.Unmarshal([]byte(RenderMetricsJSON(issues, &ReportConfig{UpdatedAfter: &since})), &decoded); err != nil {
+		t.Fatalf("metrics JSON: %v", err)
+	}
+	if len(decoded.Issues) != 2 || decoded.Groups[0].LeadTime.Percentiles["p85"] != 4 {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"net/url"
 	"os"
 	"regexp"
-	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -36,7 +35,13 @@ func RenderReport(parentIssues []*IssueData, cfg *ReportConfig) {
 
 	// Render output
 	var outputData string
-	if cfg.JSONOutput {
+	if cfg.DiffBaseline != nil {
+		outputData = RenderDiff(diffSnapshots(*cfg.DiffBaseline, parentIssues, time.Now()), cfg)
+	} else if cfg.MetricsOutput && cfg.JSONOutput {
+		outputData = RenderMetricsJSON(issuesToRender, cfg)
+	} else if cfg.MetricsOutput {
+		outputData = RenderMetricsMarkdown(issuesToRender, cfg)
+	} else if cfg.JSONOutput {
 		outputData = RenderJSONReport(issuesToRender, cfg)
 	} else if cfg.CSVOutput {
 		outputData = RenderCSVReport(issuesToRender, cfg)
@@ -44,6 +49,16 @@ func RenderReport(parentIssues []*IssueData, cfg *ReportConfig) {
 		outputData = RenderSlackReport(issuesToRender, cfg)
 	} else if cfg.URLOutput {
 		outputData = RenderURLReport(issuesToRender, cfg)
+	} else if cfg.HTMLOutput {
+		outputData = RenderHTMLReport(issuesToRender, cfg)
+	} else if cfg.DotOutput {
+		outputData = RenderDotReport(issuesToRender, cfg)
+	} else if cfg.MermaidOutput != "" {
+		outputData = RenderMermaidReport(issuesToRender, cfg)
+	} else if cfg.JiraWikiOutput {
+		outputData = RenderJiraWikiReport(issuesToRender, cfg)
+	} else if cfg.ConfluenceOutput {
+		outputData = RenderConfluenceReport(issuesToRender, cfg)
 	} else if cfg.SummaryOutput {
 		outputData = RenderMarkdownStatusSummary(issuesToRender, cfg)
 	} else if cfg.MarkdownOutput {
@@ -90,6 +105,32 @@ func escapeMarkdownInline(s string) string {
 	return b.String()
 }
 
+// truncationText explains, as plain text, that a search hit --max-results, or returns "" otherwise.
+// Each renderer adds its own "truncated:" label (see truncationNote).
+func truncationText(cfg *ReportConfig) string {
+	if cfg == nil || !cfg.Truncated {
+		return ""
+	}
+	return fmt.Sprintf("at least one query returned more than %d issues; raise --max-results (0 = unlimited)", cfg.MaxResults)
+}
+
+// truncationNote returns truncationText as a markdown warning, or "".
+func truncationNote(cfg *ReportConfig) string {
+	if text := truncationText(cfg); text != "" {
+		return "⚠️ **truncated:** " + text
+	}
+	return ""
+}
+
+// statusForDisplay returns the issue's Jira status name (lowercased like normalized statuses), or
+// the normalized Status when the name is unavailable (e.g. older cache entries).
+func statusForDisplay(issue *IssueData) string {
+	if name := strings.TrimSpace(issue.StatusName); name != "" {
+		return strings.ToLower(name)
+	}
+	return issue.Status
+}
+
 // trendingCommentForDisplay trims whitespace; empty means no comment (render as blank).
 func trendingCommentForDisplay(s string) string {
 	return strings.TrimSpace(s)
@@ -104,21 +145,60 @@ func RenderMarkdownReport(issues []*IssueData, cfg *ReportConfig) string {
 	result = append(result, fmt.Sprintf("\n### %s @ %s", escapeMarkdownInline(cfg.Title), time.Now().Format(time.RFC3339)))
 
 	result = append(result, fmt.Sprintf("* row count: %d", len(issues)))
+	if note := truncationNote(cfg); note != "" {
+		result = append(result, "* "+note)
+	}
 
-	// Render header row (type between trending and status); trending comment last
-	result = append(result, "\n| trending | type | status | issue | assignee | due date | last update | comment |")
-	result = append(result, "|---|---|---|---|:--|:--|:--|:--|")
+	for _, group := range groupIssues(issues, cfg.GroupBy) {
+		if group.Name != "" {
+			result = append(result, fmt.Sprintf("\n#### %s (%s)", escapeMarkdownInline(group.Name), groupSummary(group.Issues)))
+		}
+		result = append(result, markdownIssueTable(group.Issues, cfg)...)
+	}
+
+	result = append(result, "\n")
+	return strings.Join(result, "\n")
+}
+
+// markdownIssueTable renders the header and rows of the markdown issue table.
+func markdownIssueTable(issues []*IssueData, cfg *ReportConfig) []string {
+	if len(cfg.Columns) > 0 {
+		return markdownColumnsTable(issues, cfg)
+	}
+	var result []string
+	// Render header row (type between trending and status); trending comment last.
+	// Child rows also name the parent they belong to; --history adds a trending sparkline;
+	// --tree indents descendants under their parent and adds a rollup of each issue's children.
+	showParent := cfg != nil && cfg.RenderChildren
+	showHistory := cfg != nil && cfg.HistoryRuns > 0
+	showTree := cfg != nil && cfg.TreeOutput
+	headers, aligns := []string{"trending"}, []string{"---"}
+	if showHistory {
+		headers, aligns = append(headers, "history"), append(aligns, "---")
+	}
+	headers, aligns = append(headers, "type", "status", "issue"), append(aligns, "---", "---", "---")
+	if showTree {
+		headers, aligns = append(headers, "rollup"), append(aligns, ":--")
+	}
+	if showParent {
+		headers, aligns = append(headers, "parent"), append(aligns, ":--")
+	}
+	headers = append(headers, "assignee", "due date", "last update", "comment")
+	aligns = append(aligns, ":--", ":--", ":--", ":--")
+	result = append(result, "\n| "+strings.Join(headers, " | ")+" |")
+	result = append(result, "|"+strings.Join(aligns, "|")+"|")
 
 	// Render rows
-	for _, issue := range issues {
+	for _, row := range treeRows(issues, showTree, cfg.SortKeys) {
+		issue := row.Issue
 		// Format cells
-		issueLink := fmt.Sprintf("[%s](%s)", escapeMarkdownInline(issue.Summary), issue.URL)
+		issueLink := treeIndent(fmt.Sprintf("[%s](%s)", escapeMarkdownInline(issue.Summary), issue.URL), row.Depth, "&nbsp;&nbsp;&nbsp;")
 		trendingWithEmoji := fmt.Sprintf("%s %s", issue.TrendingEmoji, issue.Trending)
-		dueDate := FormatDate(issue.Due)
+		dueDate := dueDateWithSlips(issue)
 		timestampLink := FormatTimestampWithLink(issue.Comment.Created, issue.Comment.Url, false)
 		typeOrStatus := issue.Type
 		if typeOrStatus == "" || strings.ToLower(typeOrStatus) == "unknown" {
-			typeOrStatus = issue.Status
+			typeOrStatus = statusForDisplay(issue)
 		}
 
 		trendingCommentCell := trendingCommentForDisplay(issue.TrendingComment)
@@ -127,16 +207,79 @@ func RenderMarkdownReport(issues []*IssueData, cfg *ReportConfig) string {
 		}
 
 		// Render row
-		row := fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s |",
-			trendingWithEmoji, typeOrStatus, issue.Status, issueLink, issue.Assignee, dueDate, timestampLink, trendingCommentCell)
-		result = append(result, row)
+		cells := []string{trendingWithEmoji}
+		if showHistory {
+			cells = append(cells, trendingSparkline(issue, cfg))
+		}
+		cells = append(cells, typeOrStatus, statusForDisplay(issue), issueLink)
+		if showTree {
+			cells = append(cells, trendingRollup(issue))
+		}
+		if showParent {
+			cells = append(cells, parentLink(issue))
+		}
+		cells = append(cells, issue.Assignee, dueDate, timestampLink, trendingCommentCell)
+		result = append(result, "| "+strings.Join(cells, " | ")+" |")
 	}
+	return result
+}
 
-	result = append(result, "\n")
-	return strings.Join(result, "\n")
+// markdownColumnsTable renders the markdown issue table with the --columns set.
+func markdownColumnsTable(issues []*IssueData, cfg *ReportConfig) []string {
+	aligns := make([]string, len(cfg.Columns))
+	for i := range aligns {
+		aligns[i] = "---"
+	}
+	result := []string{"\n| " + strings.Join(cfg.Columns, " | ") + " |", "|" + strings.Join(aligns, "|") + "|"}
+	indented := treeColumn(cfg.Columns)
+	for _, row := range treeRows(issues, cfg.TreeOutput, cfg.SortKeys) {
+		cells := make([]string, len(cfg.Columns))
+		for i, column := range cfg.Columns {
+			cells[i] = columnCell(column, row.Issue, cfg, columnMarkdown)
+			if column == indented {
+				cells[i] = treeIndent(cells[i], row.Depth, "&nbsp;&nbsp;&nbsp;")
+			}
+		}
+		result = append(result, "| "+strings.Join(cells, " | ")+" |")
+	}
+	return result
+}
+
+// dueDateWithSlips formats the due date, noting how often it slipped and the original date (--changelog).
+func dueDateWithSlips(issue *IssueData) string {
+	due := FormatDate(issue.Due)
+	slips := dueSlipCount(issue)
+	if slips == 0 {
+		return due
+	}
+	times := "times"
+	if slips == 1 {
+		times = "time"
+	}
+	return fmt.Sprintf("%s (slipped %d %s, originally %s)", due, slips, times, FormatDate(issue.OriginalDue))
+}
+
+// parentLink returns a markdown link to the issue's parent, or "" when it has none.
+func parentLink(issue *IssueData) string {
+	if url := parentURL(issue); url != "" {
+		return fmt.Sprintf("[%s](%s)", issue.ParentKey, url)
+	}
+	return issue.ParentKey
+}
+
+// parentURL returns the browse URL of the issue's parent, derived from its own URL; "" when unknown.
+func parentURL(issue *IssueData) string {
+	if issue.ParentKey == "" {
+		return ""
+	}
+	i := strings.LastIndex(issue.URL, "/browse/")
+	if i < 0 {
+		return ""
+	}
+	return issue.URL[:i] + "/browse/" + issue.ParentKey
 }
 
-// RenderMarkdownStatusSummary renders a markdown table of counts and percents by issue Status
+// RenderMarkdownStatusSummary renders a markdown table of counts and percents by Jira status name
 // for the filtered list (same filters as other reports).
 func RenderMarkdownStatusSummary(issues []*IssueData, cfg *ReportConfig) string {
 	issues = filterAndSortIssues(issues, cfg)
@@ -147,7 +290,11 @@ func RenderMarkdownStatusSummary(issues []*IssueData, cfg *ReportConfig) string
 	var b strings.Builder
 	fmt.Fprintf(&b, "\n### %s — status summary @ %s\n\n", title, time.Now().Format(time.RFC3339))
 	n := len(issues)
-	fmt.Fprintf(&b, "* total issues: %d*\n\n", n)
+	fmt.Fprintf(&b, "* total issues: %d*\n", n)
+	if note := truncationNote(cfg); note != "" {
+		fmt.Fprintf(&b, "* %s\n", note)
+	}
+	b.WriteString("\n")
 	if n == 0 {
 		b.WriteString("*No issues in the filtered set.*\n\n")
 		return b.String()
@@ -158,7 +305,7 @@ func RenderMarkdownStatusSummary(issues []*IssueData, cfg *ReportConfig) string
 		if issue == nil {
 			continue
 		}
-		st := strings.TrimSpace(issue.Status)
+		st := strings.TrimSpace(statusForDisplay(issue))
 		if st == "" {
 			st = "unknown"
 		}
@@ -190,9 +337,25 @@ func RenderMarkdownStatusSummary(issues []*IssueData, cfg *ReportConfig) string
 	return b.String()
 }
 
+// jsonEnvelope is the --json-envelope shape: the issue list plus whether a search hit --max-results.
+type jsonEnvelope struct {
+	Truncated  bool         `json:"truncated"`
+	MaxResults int          `json:"max_results"`
+	Issues     []*IssueData `json:"issues"`
+}
+
+// RenderJSONReport renders issues as a JSON array, or as a jsonEnvelope object with --json-envelope.
+// The shape depends only on the flag, never on whether the results were truncated.
 func RenderJSONReport(issues []*IssueData, cfg *ReportConfig) string {
 	issues = filterAndSortIssues(issues, cfg)
-	jsonData, err := json.Marshal(issues)
+	if issues == nil {
+		issues = []*IssueData{}
+	}
+	var payload any = issues
+	if cfg != nil && cfg.JSONEnvelope {
+		payload = jsonEnvelope{Truncated: cfg.Truncated, MaxResults: cfg.MaxResults, Issues: issues}
+	}
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		logError("Failed to marshal JSON: %v", err)
 		return ""
@@ -211,6 +374,9 @@ func escapeCSVField(s string) string {
 
 func RenderCSVReport(issues []*IssueData, cfg *ReportConfig) string {
 	issues = filterAndSortIssues(issues, cfg)
+	if len(cfg.Columns) > 0 {
+		return renderCSVColumns(issues, cfg)
+	}
 
 	headers := []string{
 		"key", "url", "summary", "status", "status_emoji", "assignee", "priority",
@@ -218,6 +384,10 @@ func RenderCSVReport(issues []*IssueData, cfg *ReportConfig) string {
 		"trending", "trending_emoji", "type", "comment_url", "comment_created",
 		"trending_comment",
 	}
+	if cfg.LoadChangelog {
+		headers = append(headers, "due_slips", "original_due")
+	}
+	headers = append(headers, cfg.ExtraFields...)
 	escapedHeaders := make([]string, len(headers))
 	for i, h := range headers {
 		escapedHeaders[i] = escapeCSVField(h)
@@ -234,7 +404,7 @@ func RenderCSVReport(issues []*IssueData, cfg *ReportConfig) string {
 			issue.Key,
 			issue.URL,
 			issue.Summary,
-			issue.Status,
+			statusForDisplay(issue),
 			issue.StatusEmoji,
 			issue.Assignee,
 			issue.Priority,
@@ -248,6 +418,12 @@ func RenderCSVReport(issues []*IssueData, cfg *ReportConfig) string {
 			commentCreated,
 			trendingCommentForDisplay(issue.TrendingComment),
 		}
+		if cfg.LoadChangelog {
+			row = append(row, columnCell("due_slips", issue, cfg, columnCSV), issue.OriginalDue)
+		}
+		for _, name := range cfg.ExtraFields {
+			row = append(row, issue.Extra[name])
+		}
 		escapedRow := make([]string, len(row))
 		for i, v := range row {
 			escapedRow[i] = escapeCSVField(v)
@@ -257,20 +433,51 @@ func RenderCSVReport(issues []*IssueData, cfg *ReportConfig) string {
 	return strings.Join(result, "\n")
 }
 
+// renderCSVColumns renders the CSV report with the --columns set as its header.
+func renderCSVColumns(issues []*IssueData, cfg *ReportConfig) string {
+	escaped := func(cells []string) string {
+		out := make([]string, len(cells))
+		for i, v := range cells {
+			out[i] = escapeCSVField(v)
+		}
+		return strings.Join(out, csvSep)
+	}
+	result := []string{escaped(cfg.Columns)}
+	for _, issue := range issues {
+		cells := make([]string, len(cfg.Columns))
+		for i, column := range cfg.Columns {
+			cells[i] = columnCell(column, issue, cfg, columnCSV)
+		}
+		result = append(result, escaped(cells))
+	}
+	return strings.Join(result, "\n")
+}
+
 // RenderSlackReport renders issues as a Slack-formatted numbered list
 func RenderSlackReport(issues []*IssueData, cfg *ReportConfig) string {
 	issues = filterAndSortIssues(issues, cfg)
 
 	var result []string
-	for i, issue := range issues {
-		line := fmt.Sprintf("%d. %s [%s](%s), (due %s)", i+1, issue.TrendingEmoji, issue.Summary, issue.URL, FormatDate(issue.Due))
-		if issue.Comment.Url != "" {
-			line += fmt.Sprintf(" ([last update](%s))", issue.Comment.Url)
+	if text := truncationText(cfg); text != "" {
+		result = append(result, "⚠️ *truncated:* "+text)
+	}
+	for g, group := range groupIssues(issues, cfg.GroupBy) {
+		if group.Name != "" {
+			if g > 0 {
+				result = append(result, "")
+			}
+			result = append(result, fmt.Sprintf("*%s* (%s)", group.Name, groupSummary(group.Issues)))
 		}
-		if tc := trendingCommentForDisplay(issue.TrendingComment); tc != "" {
-			line += fmt.Sprintf(", (%s)", tc)
+		for i, issue := range group.Issues {
+			line := fmt.Sprintf("%d. %s [%s](%s), (due %s)", i+1, issue.TrendingEmoji, issue.Summary, issue.URL, FormatDate(issue.Due))
+			if issue.Comment.Url != "" {
+				line += fmt.Sprintf(" ([last update](%s))", issue.Comment.Url)
+			}
+			if tc := trendingCommentForDisplay(issue.TrendingComment); tc != "" {
+				line += fmt.Sprintf(", (%s)", tc)
+			}
+			result = append(result, line)
 		}
-		result = append(result, line)
 	}
 	return strings.Join(result, "\n")
 }
@@ -278,35 +485,81 @@ func RenderSlackReport(issues []*IssueData, cfg *ReportConfig) string {
 // RenderSimpleReport renders one line per issue as an aligned table using text/tabwriter.
 func RenderSimpleReport(issues []*IssueData, cfg *ReportConfig) string {
 	issues = filterAndSortIssues(issues, cfg)
+	note := ""
+	if text := truncationText(cfg); text != "" {
+		note = "⚠️ truncated: " + text
+	}
 	if len(issues) == 0 {
-		return ""
+		return note
 	}
 	var buf bytes.Buffer
+	if note != "" {
+		buf.WriteString(note + "\n")
+	}
 	// minwidth=4 so emoji columns get padding and align; tabwriter counts runes, not display width
 	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
 
-	// column headers: trending, status, due, type, key, summary, trending comment
-	format := "%s\t%s\t%s\t%s\t%s\t%s\t%s\n"
-	for _, issue := range issues {
-		days, ok := DaysFromNow(issue.Due)
-		dueStr := "?"
-		if ok {
-			if issue.Trending == "done" {
-				dueStr = "done"
+	// column headers: trending, [history,] status, due, type, key, summary, [rollup,] trending comment;
+	// child rows annotate their key with the parent they belong to; --tree indents descendants;
+	// --group-by starts each group with a heading line; --columns replaces the column set and adds a header line
+	showParent := cfg != nil && cfg.RenderChildren
+	showHistory := cfg != nil && cfg.HistoryRuns > 0
+	showTree := cfg != nil && cfg.TreeOutput
+	indented := treeColumn(cfg.Columns)
+	for i, group := range groupIssues(issues, cfg.GroupBy) {
+		if group.Name != "" {
+			if i > 0 {
+				fmt.Fprintln(tw)
+			}
+			fmt.Fprintf(tw, "%s (%s)\n", group.Name, groupSummary(group.Issues))
+		}
+		if len(cfg.Columns) > 0 {
+			fmt.Fprintln(tw, strings.Join(cfg.Columns, "\t"))
+		}
+		for _, row := range treeRows(group.Issues, showTree, cfg.SortKeys) {
+			issue := row.Issue
+			if len(cfg.Columns) > 0 {
+				cols := make([]string, len(cfg.Columns))
+				for c, column := range cfg.Columns {
+					cols[c] = columnCell(column, issue, cfg, columnText)
+					if column == indented {
+						cols[c] = treeIndent(cols[c], row.Depth, "  ")
+					}
+				}
+				fmt.Fprintln(tw, strings.Join(cols, "\t"))
+				continue
+			}
+			days, ok := DaysFromNow(issue.Due)
+			dueStr := "?"
+			if ok {
+				if issue.Trending == "done" {
+					dueStr = "done"
+				} else {
+					dueStr = fmt.Sprintf("due in %d days", days)
+				}
 			} else {
-				dueStr = fmt.Sprintf("due in %d days", days)
+				dueStr = "(no due date)"
+			}
+			key := issue.Key
+			if showParent && issue.ParentKey != "" {
+				key = fmt.Sprintf("%s (parent %s)", issue.Key, issue.ParentKey)
+			}
+			cols := []string{issue.TrendingEmoji}
+			if showHistory {
+				cols = append(cols, trendingSparkline(issue, cfg))
 			}
-		} else {
-			dueStr = "(no due date)"
+			cols = append(cols,
+				issue.StatusEmoji,
+				dueStr,
+				issue.Type,
+				treeIndent(key, row.Depth, "  "),
+				strings.ReplaceAll(issue.Summary, "\n", " "))
+			if showTree {
+				cols = append(cols, trendingRollup(issue))
+			}
+			cols = append(cols, trendingCommentForDisplay(issue.TrendingComment))
+			fmt.Fprintln(tw, strings.Join(cols, "\t"))
 		}
-		fmt.Fprintf(tw, format,
-			issue.TrendingEmoji,
-			issue.StatusEmoji,
-			dueStr,
-			issue.Type,
-			issue.Key,
-			strings.ReplaceAll(issue.Summary, "\n", " "),
-			trendingCommentForDisplay(issue.TrendingComment))
 	}
 	tw.Flush()
 	return strings.TrimRight(buf.String(), "\n")
@@ -469,8 +722,8 @@ func filterAndSortIssues(issues []*IssueData, cfg *ReportConfig) []*IssueData {
 	// Sort issues by status, target end, updated
 	sort.Slice(filteredIssues, func(i, j int) bool {
 		// By status priority
-		pi := GetStatusPriority(filteredIssues[i].Status)
-		pj := GetStatusPriority(filteredIssues[j].Status)
+		pi := activeStatusMap.forIssue(filteredIssues[i]).Priority
+		pj := activeStatusMap.forIssue(filteredIssues[j]).Priority
 		if pi != pj {
 			return pi < pj
 		}
@@ -498,14 +751,14 @@ func filterAndSortIssues(issues []*IssueData, cfg *ReportConfig) []*IssueData {
 		// By summary
 		return filteredIssues[i].Summary < filteredIssues[j].Summary
 	})
+	sortBySortKeys(filteredIssues, cfg.SortKeys)
 	return filteredIssues
 }
 
-// GetStatusPriority returns the sort priority for a status (index in statusOrder, or 999 if unknown)
+// GetStatusPriority returns the sort priority for a status name from activeStatusMap (999 if unmapped)
 func GetStatusPriority(statusName string) int {
-	s := strings.ToLower(strings.TrimSpace(statusName))
-	if i := slices.Index(statusOrder, s); i >= 0 {
-		return i
+	if info, ok := activeStatusMap.lookup(statusName); ok {
+		return info.Priority
 	}
 	return 999
 }
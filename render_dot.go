@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dotEscaper escapes text inside a double-quoted DOT string.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ")
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// dotLabel labels a node with its key and summary, wrapping the summary to keep boxes narrow.
+func dotLabel(key, summary string) string {
+	lines := []string{dotEscaper.Replace(key)}
+	line := ""
+	for _, word := range strings.Fields(summary) {
+		if line != "" && len(line)+1+len(word) > 32 {
+			lines = append(lines, dotEscaper.Replace(line))
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, dotEscaper.Replace(line))
+	}
+	return `"` + strings.Join(lines, `\n`) + `"`
+}
+
+// dotEdge is a parent -> child edge, or blocker -> blocked when blocking.
+type dotEdge struct {
+	From, To string
+	Blocking bool
+}
+
+// stronglyConnected returns the strongly connected components of the graph with Tarjan's algorithm,
+// visiting nodes in order so the result is deterministic.
+func stronglyConnected(nodes []string, adj map[string][]string) [][]string {
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var components [][]string
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range adj[v] {
+			if _, visited := index[w]; !visited {
+				strongConnect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] == index[v] {
+			var component []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	for _, v := range nodes {
+		if _, visited := index[v]; !visited {
+			strongConnect(v)
+		}
+	}
+	return components
+}
+
+// blockingCycles returns the blocking cycles among edges (components of more than one issue, or an
+// issue blocking itself), each sorted by key, and each cycle member's cycle index.
+func blockingCycles(nodes []string, edges []dotEdge) ([][]string, map[string]int) {
+	adj := map[string][]string{}
+	selfLoop := map[string]bool{}
+	for _, e := range edges {
+		if !e.Blocking {
+			continue
+		}
+		adj[e.From] = append(adj[e.From], e.To)
+		if e.From == e.To {
+			selfLoop[e.From] = true
+		}
+	}
+	var cycles [][]string
+	cycleOf := map[string]int{}
+	for _, component := range stronglyConnected(nodes, adj) {
+		if len(component) < 2 && !selfLoop[component[0]] {
+			continue
+		}
+		sort.Strings(component)
+		for _, key := range component {
+			cycleOf[key] = len(cycles)
+		}
+		cycles = append(cycles, component)
+	}
+	return cycles, cycleOf
+}
+
+// RenderDotReport renders issues, their loaded children and their "blocks" links as a Graphviz digraph
+// (--dot). Nodes are coloured by trending and labeled with key and summary; linked issues outside the
+// report are dashed. Blocking cycles and issues blocked by off-track work are outlined in red.
+func RenderDotReport(issues []*IssueData, cfg *ReportConfig) string {
+	issues = filterAndSortIssues(issues, cfg)
+
+	var nodes []*IssueData
+	byKey := map[string]*IssueData{}
+	for _, issue := range flattenIssues(issues) {
+		if _, ok := byKey[issue.Key]; !ok {
+			byKey[issue.Key] = issue
+			nodes = append(nodes, issue)
+		}
+	}
+
+	var edges []dotEdge
+	seenEdge := map[dotEdge]bool{}
+	addEdge := func(e dotEdge) {
+		if e.From != "" && e.To != "" && !seenEdge[e] {
+			seenEdge[e] = true
+			edges = append(edges, e)
+		}
+	}
+	var external []LinkedIssue
+	addExternal := func(l LinkedIssue) {
+		if _, ok := byKey[l.Key]; !ok && l.Key != "" {
+			byKey[l.Key] = nil
+			external = append(external, l)
+		}
+	}
+	for _, issue := range nodes {
+		for _, child := range filterAndSortIssues(issue.Children, &ReportConfig{}) {
+			addEdge(dotEdge{From: issue.Key, To: child.Key})
+		}
+	}
+	for _, issue := range nodes {
+		for _, l := range issue.Blocks {
+			addEdge(dotEdge{From: issue.Key, To: l.Key, Blocking: true})
+			addExternal(l)
+		}
+		for _, l := range issue.BlockedBy {
+			addEdge(dotEdge{From: l.Key, To: issue.Key, Blocking: true})
+			addExternal(l)
+		}
+	}
+
+	keys := make([]string, 0, len(nodes)+len(external))
+	for _, issue := range nodes {
+		keys = append(keys, issue.Key)
+	}
+	for _, l := range external {
+		keys = append(keys, l.Key)
+	}
+	cycles, cycleOf := blockingCycles(keys, edges)
+	blockedByOffTrack := map[string]bool{}
+	for _, e := range edges {
+		if blocker := byKey[e.From]; e.Blocking && blocker != nil && blocker.Trending == "off track" {
+			blockedByOffTrack[e.To] = true
+		}
+	}
+
+	lines := []string{
+		"digraph snippets {",
+		fmt.Sprintf("  label=%s;", dotQuote(cfg.Title)),
+		"  labelloc=t;",
+		"  rankdir=LR;",
+		`  node [shape=box, style="rounded,filled", fontname="Helvetica", fontcolor="#FFFFFF"];`,
+		`  edge [fontname="Helvetica", fontsize=10, color="#6B778C"];`,
+	}
+	for _, issue := range nodes {
+		attrs := []string{"label=" + dotLabel(issue.Key, issue.Summary)}
+		if colour, ok := jiraWikiColours[issue.Trending]; ok {
+			attrs = append(attrs, fmt.Sprintf(`fillcolor="%s"`, colour))
+		} else {
+			attrs = append(attrs, `fillcolor="#DFE1E6"`, `fontcolor="#172B4D"`)
+		}
+		if issue.URL != "" {
+			attrs = append(attrs, "URL="+dotQuote(issue.URL))
+		}
+		if _, ok := cycleOf[issue.Key]; ok {
+			attrs = append(attrs, `color="#BF2600"`, "penwidth=3", "peripheries=2")
+		} else if blockedByOffTrack[issue.Key] {
+			attrs = append(attrs, `color="#FF5630"`, "penwidth=3", `xlabel="blocked by off track"`)
+		}
+		lines = append(lines, fmt.Sprintf("  %s [%s];", dotQuote(issue.Key), strings.Join(attrs, ", ")))
+	}
+	for _, l := range external {
+		attrs := []string{"label=" + dotLabel(l.Key, l.Summary), `style="rounded,dashed"`, `fontcolor="#172B4D"`}
+		if _, ok := cycleOf[l.Key]; ok {
+			attrs = append(attrs, `color="#BF2600"`, "penwidth=3", "peripheries=2")
+		}
+		lines = append(lines, fmt.Sprintf("  %s [%s];", dotQuote(l.Key), strings.Join(attrs, ", ")))
+	}
+	for _, e := range edges {
+		line := fmt.Sprintf("  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if e.Blocking {
+			from, inFrom := cycleOf[e.From]
+			to, inTo := cycleOf[e.To]
+			if inFrom && inTo && from == to {
+				line += ` [label="blocks", style=bold, color="#BF2600", penwidth=2.5]`
+			} else {
+				line += ` [label="blocks", style=dashed, color="#FF5630"]`
+			}
+		}
+		lines = append(lines, line+";")
+	}
+	for _, cycle := range cycles {
+		lines = append(lines, "  // blocking cycle: "+strings.Join(cycle, ", "))
+	}
+	lines = append(lines, "}")
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStronglyConnected(t *testing.T) {
+	adj := map[string][]string{"A": {"B"}, "B": {"C"}, "C": {"A", "D"}, "D": {"E"}}
+	got := stronglyConnected([]string{"A", "B", "C", "D", "E"}, adj)
+	var sizes []int
+	for _, c := range got {
+		sizes = append(sizes, len(c))
+	}
+	if !reflect.DeepEqual(sizes, []int{1, 1, 3}) {
+		t.Errorf("components = %v, want E, D, then the A-B-C cycle", got)
+	}
+}
+
+func TestRenderDotReport(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "E-1", URL: "https://jira/browse/E-1", Summary: `Ship the "new" login flow for all customers`, Status: "in progress", Trending: "off track",
+			Blocks:   []LinkedIssue{{Key: "E-2"}},
+			Children: []*IssueData{{Key: "S-1", Summary: "Story", Status: "new", Trending: "on track"}}},
+		{Key: "E-2", Summary: "Billing", Status: "in progress", Trending: "at risk",
+			BlockedBy: []LinkedIssue{{Key: "E-1"}}, Blocks: []LinkedIssue{{Key: "X-9", Summary: "Vendor"}}},
+		{Key: "E-3", Summary: "Loop", Status: "in progress", Trending: "on track",
+			Blocks: []LinkedIssue{{Key: "X-9"}}, BlockedBy: []LinkedIssue{{Key: "X-9"}}},
+	}
+	out := RenderDotReport(issues, &ReportConfig{Title: "Plan"})
+	if !strings.HasPrefix(out, "digraph snippets {\n  label=\"Plan\";") || !strings.HasSuffix(out, "\n}") {
+		t.Fatalf("not a digraph:\n%s", out)
+	}
+	for _, want := range []string{
+		`"E-1" [label="E-1\nShip the \"new\" login flow for\nall customers", fillcolor="#FF5630", URL="https://jira/browse/E-1"];`,
+		`"S-1" [label="S-1\nStory", fillcolor="#36B37E"];`,
+		`"E-2" [label="E-2\nBilling", fillcolor="#FFAB00", color="#FF5630", penwidth=3, xlabel="blocked by off track"];`,
+		`"X-9" [label="X-9\nVendor", style="rounded,dashed", fontcolor="#172B4D", color="#BF2600", penwidth=3, peripheries=2];`,
+		`"E-1" -> "S-1";`,
+		`"E-1" -> "E-2" [label="blocks", style=dashed, color="#FF5630"];`,
+		`"E-3" -> "X-9" [label="blocks", style=bold, color="#BF2600", penwidth=2.5];`,
+		`"X-9" -> "E-3" [label="blocks", style=bold, color="#BF2600", penwidth=2.5];`,
+		"// blocking cycle: E-3, X-9",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, `"E-1" -> "E-2"`); n != 1 {
+		t.Errorf("a link seen from both ends should be drawn once, got %d", n)
+	}
+	if strings.Count(out, `[label="X-9`) != 1 {
+		t.Error("an external issue should be declared once")
+	}
+}
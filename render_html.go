@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// htmlTrendingOrder ranks trending values for the sortable table (worst first).
+var htmlTrendingOrder = map[string]int{"off track": 0, "at risk": 1, "not started": 2, "on track": 3, "done": 4}
+
+// htmlRow is one issue in the HTML report; Children are rendered as a collapsible nested table.
+type htmlRow struct {
+	Issue         *IssueData
+	TrendingClass string
+	TrendingRank  int
+	Status        string
+	StatusRank    int
+	Due           string
+	DueSort       string
+	ShowParent    bool // --render-children: the row names its parent
+	ParentKey     string
+	ParentURL     string
+	CommentDate   string
+	Children      []htmlRow
+	Rollup        string // --tree: trendingRollup of Children, shown with them expanded
+}
+
+// htmlReport is the data for htmlReportTemplate.
+type htmlReport struct {
+	Title          string
+	GeneratedAt    string
+	Count          int
+	TruncationNote string
+	ShowParent     bool
+	Columns        []string // --columns: the table has these columns (Cells) instead of the fixed set (Rows)
+	Groups         []htmlGroup
+}
+
+// htmlGroup is one --group-by section (a sortable table of its own); ungrouped reports have one unnamed group.
+type htmlGroup struct {
+	Name    string
+	Summary string
+	Rows    []htmlRow
+	Cells   [][]htmlCell
+}
+
+// htmlCell is one --columns cell; Sort overrides the text for the table sorter, Indent places a --tree
+// row under its parent.
+type htmlCell struct {
+	Text   string
+	Href   string
+	Sort   string
+	Indent string
+}
+
+// badgeClass turns a trending or status value into a CSS class suffix, e.g. "on track" -> "on-track".
+func badgeClass(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "unknown"
+	}
+	return strings.Join(strings.Fields(s), "-")
+}
+
+func newHTMLRow(issue *IssueData, showParent, withChildren, tree bool, sortKeys []SortKey) htmlRow {
+	rank, ok := htmlTrendingOrder[issue.Trending]
+	if !ok {
+		rank = len(htmlTrendingOrder)
+	}
+	dueSort := issue.Due
+	if dueSort == "" {
+		dueSort = "9999-99-99"
+	}
+	row := htmlRow{
+		Issue:         issue,
+		TrendingClass: badgeClass(issue.Trending),
+		TrendingRank:  rank,
+		Status:        statusForDisplay(issue),
+		StatusRank:    activeStatusMap.forIssue(issue).Priority,
+		Due:           dueDateWithSlips(issue),
+		DueSort:       dueSort,
+		ShowParent:    showParent,
+		ParentKey:     issue.ParentKey,
+		ParentURL:     parentURL(issue),
+	}
+	if issue.Comment.Url != "" {
+		row.CommentDate = FormatDate(issue.Comment.Created)
+	}
+	if withChildren {
+		for _, child := range filterAndSortIssues(issue.Children, &ReportConfig{SortKeys: sortKeys}) {
+			row.Children = append(row.Children, newHTMLRow(child, false, true, tree, sortKeys))
+		}
+		if tree {
+			row.Rollup = trendingRollup(issue)
+		}
+	}
+	return row
+}
+
+// htmlColumnCells renders a --columns row; ranked and date columns sort by value rather than text.
+func htmlColumnCells(row treeRow, cfg *ReportConfig) []htmlCell {
+	indented := treeColumn(cfg.Columns)
+	cells := make([]htmlCell, len(cfg.Columns))
+	for i, column := range cfg.Columns {
+		cell := htmlCell{Text: columnCell(column, row.Issue, cfg, columnText), Href: columnLink(column, row.Issue)}
+		switch column {
+		case "trending", "status", "priority":
+			rank, _, _ := sortValue(row.Issue, column)
+			cell.Sort = strconv.Itoa(rank)
+		case "due":
+			cell.Sort = row.Issue.Due
+			if cell.Sort == "" {
+				cell.Sort = "9999-99-99"
+			}
+		}
+		if column == indented {
+			cell.Indent = treeIndent("", row.Depth, "\u00a0\u00a0\u00a0")
+		}
+		cells[i] = cell
+	}
+	return cells
+}
+
+// RenderHTMLReport renders a standalone HTML document (inline CSS and script, no external assets) with a
+// sortable issue table; loaded children of each issue are collapsible (--html), and expanded with a
+// rollup of their trending with --tree.
+func RenderHTMLReport(issues []*IssueData, cfg *ReportConfig) string {
+	return renderHTMLReport(issues, cfg, time.Now())
+}
+
+func renderHTMLReport(issues []*IssueData, cfg *ReportConfig, now time.Time) string {
+	issues = filterAndSortIssues(issues, cfg)
+	report := htmlReport{
+		Title:          cfg.Title,
+		GeneratedAt:    now.Format(time.RFC3339),
+		Count:          len(issues),
+		TruncationNote: truncationText(cfg),
+		ShowParent:     cfg.RenderChildren,
+		Columns:        cfg.Columns,
+	}
+	for _, group := range groupIssues(issues, cfg.GroupBy) {
+		g := htmlGroup{Name: group.Name, Summary: groupSummary(group.Issues)}
+		if len(cfg.Columns) > 0 {
+			for _, row := range treeRows(group.Issues, cfg.TreeOutput, cfg.SortKeys) {
+				g.Cells = append(g.Cells, htmlColumnCells(row, cfg))
+			}
+			report.Groups = append(report.Groups, g)
+			continue
+		}
+		for _, issue := range group.Issues {
+			g.Rows = append(g.Rows, newHTMLRow(issue, cfg.RenderChildren, !cfg.RenderChildren, cfg.TreeOutput, cfg.SortKeys))
+		}
+		report.Groups = append(report.Groups, g)
+	}
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, report); err != nil {
+		logError("Failed to render HTML: %v", err)
+		return ""
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"plural": func(n int, one, many string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, one)
+		}
+		return fmt.Sprintf("%d %s", n, many)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; color: #1f2328; margin: 24px; }
+h1 { font-size: 20px; margin: 0 0 4px; }
+p.meta { color: #59636e; margin: 0 0 16px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d1d9e0; padding: 6px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; cursor: pointer; white-space: nowrap; }
+th[aria-sort="ascending"]::after { content: " ▲"; }
+th[aria-sort="descending"]::after { content: " ▼"; }
+tbody tr:nth-child(even) { background: #fbfcfd; }
+table.children { margin-top: 6px; font-size: 13px; }
+table.children th { cursor: default; }
+details summary { cursor: pointer; color: #59636e; }
+a { color: #0969da; text-decoration: none; }
+.badge { display: inline-block; border-radius: 10px; padding: 1px 8px; font-size: 12px; font-weight: 600; white-space: nowrap; background: #eff2f5; color: #1f2328; }
+.badge.done { background: #ddf4ff; color: #0550ae; }
+.badge.on-track { background: #dafbe1; color: #116329; }
+.badge.at-risk { background: #fff8c5; color: #7d4e00; }
+.badge.off-track { background: #ffebe9; color: #a40e26; }
+.badge.not-started { background: #eff2f5; color: #59636e; }
+.badge.status { background: #f6f8fa; border: 1px solid #d1d9e0; font-weight: normal; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{plural .Count "issue" "issues"}} · generated {{.GeneratedAt}}</p>
+{{- if .TruncationNote}}
+<p class="meta">⚠️ <strong>truncated:</strong> {{.TruncationNote}}</p>
+{{- end}}
+{{- range .Groups}}
+{{- if .Name}}
+<h2>{{.Name}} <small>{{.Summary}}</small></h2>
+{{- end}}
+<table class="report">
+<thead>
+{{if $.Columns}}<tr>{{range $.Columns}}<th>{{.}}</th>{{end}}</tr>{{else}}<tr><th>trending</th><th>type</th><th>status</th><th>issue</th>{{if $.ShowParent}}<th>parent</th>{{end}}<th>assignee</th><th>due date</th><th>last update</th><th>comment</th></tr>{{end}}
+</thead>
+<tbody>
+{{- range .Cells}}
+<tr>{{range .}}<td{{if .Sort}} data-sort="{{.Sort}}"{{end}}>{{.Indent}}{{if .Href}}<a href="{{.Href}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}</td>{{end}}</tr>
+{{- end}}
+{{- range .Rows}}
+{{template "row" .}}
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+<script>
+document.querySelectorAll("table.report > thead th").forEach(function (th) {
+  var col = th.cellIndex;
+  th.addEventListener("click", function () {
+    var tbody = th.closest("table").tBodies[0];
+    var asc = th.getAttribute("aria-sort") !== "ascending";
+    th.parentNode.querySelectorAll("th").forEach(function (h) { h.removeAttribute("aria-sort"); });
+    th.setAttribute("aria-sort", asc ? "ascending" : "descending");
+    var key = function (tr) {
+      var td = tr.children[col];
+      var v = td.getAttribute("data-sort");
+      return v !== null ? v : td.textContent.trim().toLowerCase();
+    };
+    Array.from(tbody.rows).sort(function (a, b) {
+      var x = key(a), y = key(b);
+      var c = (!isNaN(x) && !isNaN(y) && x !== "" && y !== "") ? x - y : x.localeCompare(y);
+      return asc ? c : -c;
+    }).forEach(function (tr) { tbody.appendChild(tr); });
+  });
+});
+</script>
+</body>
+</html>
+{{define "row"}}<tr>
+<td data-sort="{{.TrendingRank}}"><span class="badge {{.TrendingClass}}">{{.Issue.TrendingEmoji}} {{.Issue.Trending}}</span></td>
+<td>{{.Issue.Type}}</td>
+<td data-sort="{{.StatusRank}}"><span class="badge status">{{.Issue.StatusEmoji}} {{.Status}}</span></td>
+<td><a href="{{.Issue.URL}}">{{.Issue.Summary}}</a> <small>{{.Issue.Key}}</small>
+{{- if .Children}}
+<details{{if .Rollup}} open{{end}}><summary>{{plural (len .Children) "child" "children"}}{{if .Rollup}} · {{.Rollup}}{{end}}</summary>
+<table class="children">
+{{template "childhead"}}
+<tbody>
+{{- range .Children}}
+{{template "childrow" .}}
+{{- end}}
+</tbody>
+</table>
+</details>
+{{- end}}</td>
+{{- if .ShowParent}}
+<td>{{if .ParentURL}}<a href="{{.ParentURL}}">{{.ParentKey}}</a>{{else}}{{.ParentKey}}{{end}}</td>
+{{- end}}
+<td>{{.Issue.Assignee}}</td>
+<td data-sort="{{.DueSort}}">{{.Due}}</td>
+<td>{{if .CommentDate}}<a href="{{.Issue.Comment.Url}}">{{.CommentDate}}</a>{{else}}N/A{{end}}</td>
+<td>{{.Issue.TrendingComment}}</td>
+</tr>{{end}}
+{{define "childhead"}}<thead>
+<tr><th>trending</th><th>type</th><th>status</th><th>issue</th><th>assignee</th><th>due date</th><th>last update</th><th>comment</th></tr>
+</thead>{{end}}
+{{define "childrow"}}<tr>
+<td><span class="badge {{.TrendingClass}}">{{.Issue.TrendingEmoji}} {{.Issue.Trending}}</span></td>
+<td>{{.Issue.Type}}</td>
+<td><span class="badge status">{{.Issue.StatusEmoji}} {{.Status}}</span></td>
+<td><a href="{{.Issue.URL}}">{{.Issue.Summary}}</a> <small>{{.Issue.Key}}</small>
+{{- if .Children}}
+<details{{if .Rollup}} open{{end}}><summary>{{plural (len .Children) "child" "children"}}{{if .Rollup}} · {{.Rollup}}{{end}}</summary>
+<table class="children">
+{{template "childhead"}}
+<tbody>
+{{- range .Children}}
+{{template "childrow" .}}
+{{- end}}
+</tbody>
+</table>
+</details>
+{{- end}}</td>
+<td>{{.Issue.Assignee}}</td>
+<td>{{.Due}}</td>
+<td>{{if .CommentDate}}<a href="{{.Issue.Comment.Url}}">{{.CommentDate}}</a>{{else}}N/A{{end}}</td>
+<td>{{.Issue.TrendingComment}}</td>
+</tr>{{end}}
+`))
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/*.golden files")
+
+// checkGolden compares got with testdata/name, rewriting it with go test -run ... -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from golden file; rerun with -update if the change is intended.\ngot:\n%s", path, got)
+	}
+}
+
+var htmlTestTime = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func htmlTestIssues() []*IssueData {
+	return []*IssueData{
+		{
+			Key: "E-1", URL: "https://jira.example.com/browse/E-1", Summary: `Launch <beta> & "friends"`,
+			Status: "in progress", StatusName: "In Progress", StatusEmoji: "🏃", Type: "Epic", Assignee: "Ann",
+			Due: "2025-04-01", DueSlips: intPtr(1), OriginalDue: "2025-03-15", Trending: "at risk", TrendingEmoji: "🟡",
+			TrendingComment: "child S-2 is 'at risk'",
+			Comment:         IssueComment{Url: "https://jira.example.com/browse/E-1?focusedCommentId=1", Created: "2025-02-27T10:00:00.000+0000"},
+			Children: []*IssueData{
+				{Key: "S-1", URL: "https://jira.example.com/browse/S-1", Summary: "Done story", Status: "closed", StatusName: "Closed",
+					StatusEmoji: "✅", Type: "Story", ParentKey: "E-1", Trending: "done", TrendingEmoji: "✅"},
+				{Key: "S-2", URL: "https://jira.example.com/browse/S-2", Summary: "Risky story", Status: "in progress",
+					StatusEmoji: "🏃", Type: "Story", ParentKey: "E-1", Due: "2025-03-20", Trending: "at risk", TrendingEmoji: "🟡"},
+			},
+		},
+		{
+			Key: "E-2", URL: "https://jira.example.com/browse/E-2", Summary: "Blocked epic", Status: "blocked",
+			StatusEmoji: "🛑", Type: "Epic", Trending: "off track", TrendingEmoji: "🔴",
+		},
+	}
+}
+
+func TestRenderHTMLReport_golden(t *testing.T) {
+	got := renderHTMLReport(htmlTestIssues(), &ReportConfig{Title: "Weekly <status>"}, htmlTestTime)
+	checkGolden(t, "report.html.golden", got)
+
+	for _, want := range []string{
+		"Launch &lt;beta&gt; &amp; &#34;friends&#34;", // summaries are escaped
+		"<details><summary>2 children</summary>",
+		`<span class="badge at-risk">`,
+		"2025-04-01 (slipped 1 time, originally 2025-03-15)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(got, "<link") || strings.Contains(got, "src=") {
+		t.Error("HTML report must not reference external assets")
+	}
+}
+
+func TestRenderHTMLReport_renderChildrenGolden(t *testing.T) {
+	children := issuesForReport(htmlTestIssues(), &ReportConfig{RenderChildren: true})
+	cfg := &ReportConfig{Title: "Children", RenderChildren: true, Truncated: true, MaxResults: 2}
+	checkGolden(t, "report_children.html.golden", renderHTMLReport(children, cfg, htmlTestTime))
+}
+
+func TestRenderHTMLReport_nestedChildTablesHaveHeaders(t *testing.T) {
+	got := renderHTMLReport(treeTestIssues(), &ReportConfig{Title: "Tree", TreeOutput: true}, htmlTestTime)
+	// the report table, E-1's children and S-2's grandchild each label their columns
+	if n := strings.Count(got, "<thead>"); n != 3 {
+		t.Errorf("<thead> count = %d, want 3:\n%s", n, got)
+	}
+}
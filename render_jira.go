@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// jiraWikiColours are the {color} values for trending cells in wiki markup.
+var jiraWikiColours = map[string]string{
+	"done":        "#6554C0",
+	"on track":    "#36B37E",
+	"at risk":     "#FFAB00",
+	"off track":   "#FF5630",
+	"not started": "#97A0AF",
+}
+
+// adfStatusColours are the ADF status lozenge colours for trending cells.
+var adfStatusColours = map[string]string{
+	"done":        "purple",
+	"on track":    "green",
+	"at risk":     "yellow",
+	"off track":   "red",
+	"not started": "neutral",
+}
+
+// jiraWikiEscaper backslash-escapes every character that starts wiki markup: tables, links, macros,
+// images (!), lists (#), and text effects (* _ ?? - + ^ ~).
+var jiraWikiEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "[", `\[`, "]", `\]`, "{", `\{`, "}", `\}`, "!", `\!`, "#", `\#`,
+	"*", `\*`, "_", `\_`, "?", `\?`, "-", `\-`, "+", `\+`, "^", `\^`, "~", `\~`, "\n", " ",
+)
+
+// escapeJiraWiki makes s literal inside a wiki table cell; empty cells become a space, which wiki tables need.
+func escapeJiraWiki(s string) string {
+	s = jiraWikiEscaper.Replace(strings.TrimSpace(s))
+	if s == "" {
+		return " "
+	}
+	return s
+}
+
+// jiraWikiPlainCell returns generated text that holds no markup (issue keys, dates) as a table cell,
+// unescaped so hyphens stay readable in the source.
+func jiraWikiPlainCell(s string) string {
+	if s = strings.TrimSpace(s); s == "" {
+		return " "
+	}
+	return s
+}
+
+// RenderJiraWikiReport renders the issue table for pasting or posting into Jira (--jira-wiki): wiki markup
+// for Jira Server/Data Center, ADF JSON for Jira Cloud, decided by cfg.JiraServer like the API version.
+func RenderJiraWikiReport(issues []*IssueData, cfg *ReportConfig) string {
+	if jiraAPIVersion(cfg.JiraServer) == "3" {
+		return RenderADFReport(issues, cfg)
+	}
+	return RenderWikiMarkupReport(issues, cfg)
+}
+
+// RenderWikiMarkupReport renders issues as Jira wiki markup: ||header|| tables, [summary|url] links and
+// {color} trending cells.
+func RenderWikiMarkupReport(issues []*IssueData, cfg *ReportConfig) string {
+	issues = filterAndSortIssues(issues, cfg)
+	showParent := cfg.RenderChildren
+
+	var result []string
+	result = append(result, fmt.Sprintf("h3. %s @ %s", escapeJiraWiki(cfg.Title), time.Now().Format(time.RFC3339)))
+	result = append(result, fmt.Sprintf("* row count: %d", len(issues)))
+	if text := truncationText(cfg); text != "" {
+		result = append(result, "* ⚠️ *truncated:* "+escapeJiraWiki(text))
+	}
+	result = append(result, "")
+
+	if len(cfg.Columns) > 0 {
+		result = append(result, "||"+strings.Join(cfg.Columns, "||")+"||")
+		indented := treeColumn(cfg.Columns)
+		for _, row := range treeRows(issues, cfg.TreeOutput, cfg.SortKeys) {
+			cells := make([]string, len(cfg.Columns))
+			for i, column := range cfg.Columns {
+				cells[i] = wikiColumnCell(column, row.Issue, cfg)
+				if column == indented {
+					cells[i] = treeIndent(cells[i], row.Depth, "\u00a0\u00a0\u00a0")
+				}
+			}
+			result = append(result, "|"+strings.Join(cells, "|")+"|")
+		}
+		return strings.Join(result, "\n")
+	}
+
+	headers := []string{"trending", "type", "status", "issue"}
+	if showParent {
+		headers = append(headers, "parent")
+	}
+	headers = append(headers, "assignee", "due date", "last update", "comment")
+	result = append(result, "||"+strings.Join(headers, "||")+"||")
+
+	for _, issue := range issues {
+		trending := wikiTrendingCell(issue)
+		lastUpdate := "N/A"
+		if issue.Comment.Url != "" {
+			lastUpdate = fmt.Sprintf("[%s|%s]", FormatDate(issue.Comment.Created), issue.Comment.Url)
+		}
+		cells := []string{
+			trending,
+			escapeJiraWiki(issue.Type),
+			escapeJiraWiki(statusForDisplay(issue)),
+			fmt.Sprintf("[%s|%s]", escapeJiraWiki(issue.Summary), issue.URL),
+		}
+		if showParent {
+			cells = append(cells, jiraWikiPlainCell(issue.ParentKey))
+		}
+		cells = append(cells,
+			escapeJiraWiki(issue.Assignee),
+			jiraWikiPlainCell(dueDateWithSlips(issue)),
+			lastUpdate,
+			escapeJiraWiki(trendingCommentForDisplay(issue.TrendingComment)))
+		result = append(result, "|"+strings.Join(cells, "|")+"|")
+	}
+	return strings.Join(result, "\n")
+}
+
+// wikiTrendingCell renders an issue's trending as a {color} wiki cell.
+func wikiTrendingCell(issue *IssueData) string {
+	trending := escapeJiraWiki(fmt.Sprintf("%s %s", issue.TrendingEmoji, issue.Trending))
+	if colour, ok := jiraWikiColours[issue.Trending]; ok {
+		trending = fmt.Sprintf("{color:%s}%s{color}", colour, trending)
+	}
+	return trending
+}
+
+// jiraWikiPlainColumns are the --columns whose values are generated (keys, dates, counts, URLs) and so
+// are written unescaped, like jiraWikiPlainCell.
+var jiraWikiPlainColumns = map[string]bool{
+	"key": true, "url": true, "created": true, "updated": true, "due": true, "due_slips": true,
+	"original_due": true, "last_update": true, "comment_url": true, "parent": true,
+}
+
+// wikiColumnCell renders one --columns cell as wiki markup, linking keys, summaries, parents and comments.
+func wikiColumnCell(column string, issue *IssueData, cfg *ReportConfig) string {
+	if column == "trending" {
+		return wikiTrendingCell(issue)
+	}
+	text := columnCell(column, issue, cfg, columnText)
+	cell := escapeJiraWiki(text)
+	if jiraWikiPlainColumns[column] {
+		cell = jiraWikiPlainCell(text)
+	}
+	if href := columnLink(column, issue); href != "" && strings.TrimSpace(text) != "" {
+		return fmt.Sprintf("[%s|%s]", cell, href)
+	}
+	return cell
+}
+
+// adfNode is a node of an Atlassian Document Format document (Jira Cloud rich text).
+type adfNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"` // doc only
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []adfNode      `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Marks   []adfMark      `json:"marks,omitempty"`
+}
+
+type adfMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+func adfText(s string) adfNode {
+	return adfNode{Type: "text", Text: s}
+}
+
+func adfLink(text, href string) adfNode {
+	if text == "" {
+		text = href
+	}
+	return adfNode{Type: "text", Text: text, Marks: []adfMark{{Type: "link", Attrs: map[string]any{"href": href}}}}
+}
+
+// adfParagraph wraps inline nodes, dropping empty text (ADF rejects empty text nodes).
+func adfParagraph(inline ...adfNode) adfNode {
+	p := adfNode{Type: "paragraph"}
+	for _, n := range inline {
+		if n.Type == "text" && n.Text == "" {
+			continue
+		}
+		p.Content = append(p.Content, n)
+	}
+	return p
+}
+
+func adfCell(cellType string, inline ...adfNode) adfNode {
+	return adfNode{Type: cellType, Attrs: map[string]any{}, Content: []adfNode{adfParagraph(inline...)}}
+}
+
+// adfTrendingStatus renders an issue's trending as a coloured status lozenge.
+func adfTrendingStatus(issue *IssueData) adfNode {
+	colour, ok := adfStatusColours[issue.Trending]
+	if !ok {
+		colour = "neutral"
+	}
+	trending := issue.Trending
+	if trending == "" {
+		trending = "unknown"
+	}
+	return adfNode{Type: "status", Attrs: map[string]any{"text": trending, "color": colour}}
+}
+
+// adfColumnCell renders the content of one --columns cell, linking keys, summaries, parents and comments.
+func adfColumnCell(column string, issue *IssueData, cfg *ReportConfig) adfNode {
+	if column == "trending" {
+		return adfTrendingStatus(issue)
+	}
+	text := columnCell(column, issue, cfg, columnText)
+	if href := columnLink(column, issue); href != "" && text != "" {
+		return adfLink(text, href)
+	}
+	return adfText(text)
+}
+
+// buildADFReport builds the issue table as an ADF document, with status lozenges for trending.
+func buildADFReport(issues []*IssueData, cfg *ReportConfig) adfNode {
+	issues = filterAndSortIssues(issues, cfg)
+	showParent := cfg.RenderChildren
+
+	doc := adfNode{Type: "doc", Version: 1}
+	doc.Content = append(doc.Content,
+		adfNode{Type: "heading", Attrs: map[string]any{"level": 3}, Content: []adfNode{adfText(fmt.Sprintf("%s @ %s", cfg.Title, time.Now().Format(time.RFC3339)))}},
+		adfParagraph(adfText(fmt.Sprintf("row count: %d", len(issues)))))
+	if text := truncationText(cfg); text != "" {
+		doc.Content = append(doc.Content, adfParagraph(adfText("⚠️ "),
+			adfNode{Type: "text", Text: "truncated:", Marks: []adfMark{{Type: "strong"}}}, adfText(" "+text)))
+	}
+
+	headers := []string{"trending", "type", "status", "issue"}
+	if showParent {
+		headers = append(headers, "parent")
+	}
+	headers = append(headers, "assignee", "due date", "last update", "comment")
+	if len(cfg.Columns) > 0 {
+		headers = cfg.Columns
+	}
+	header := adfNode{Type: "tableRow"}
+	for _, h := range headers {
+		header.Content = append(header.Content, adfCell("tableHeader", adfText(h)))
+	}
+	table := adfNode{Type: "table", Attrs: map[string]any{"isNumberColumnEnabled": false, "layout": "default"}, Content: []adfNode{header}}
+
+	if len(cfg.Columns) > 0 {
+		indented := treeColumn(cfg.Columns)
+		for _, tr := range treeRows(issues, cfg.TreeOutput, cfg.SortKeys) {
+			row := adfNode{Type: "tableRow"}
+			for _, column := range cfg.Columns {
+				var indent string
+				if column == indented {
+					indent = treeIndent("", tr.Depth, "\u00a0\u00a0\u00a0")
+				}
+				row.Content = append(row.Content, adfCell("tableCell", adfText(indent), adfColumnCell(column, tr.Issue, cfg)))
+			}
+			table.Content = append(table.Content, row)
+		}
+		doc.Content = append(doc.Content, table)
+		return doc
+	}
+
+	for _, issue := range issues {
+		lastUpdate := adfText("N/A")
+		if issue.Comment.Url != "" {
+			lastUpdate = adfLink(FormatDate(issue.Comment.Created), issue.Comment.Url)
+		}
+		row := adfNode{Type: "tableRow", Content: []adfNode{
+			adfCell("tableCell", adfTrendingStatus(issue)),
+			adfCell("tableCell", adfText(issue.Type)),
+			adfCell("tableCell", adfText(statusForDisplay(issue))),
+			adfCell("tableCell", adfLink(issue.Summary, issue.URL)),
+		}}
+		if showParent {
+			row.Content = append(row.Content, adfCell("tableCell", adfText(issue.ParentKey)))
+		}
+		row.Content = append(row.Content,
+			adfCell("tableCell", adfText(issue.Assignee)),
+			adfCell("tableCell", adfText(dueDateWithSlips(issue))),
+			adfCell("tableCell", lastUpdate),
+			adfCell("tableCell", adfText(trendingCommentForDisplay(issue.TrendingComment))))
+		table.Content = append(table.Content, row)
+	}
+	doc.Content = append(doc.Content, table)
+	return doc
+}
+
+// RenderADFReport renders the issue table as ADF JSON, ready to post as a Jira Cloud description or comment body.
+func RenderADFReport(issues []*IssueData, cfg *ReportConfig) string {
+	data, err := json.MarshalIndent(buildADFReport(issues, cfg), "", "  ")
+	if err != nil {
+		logError("Failed to marshal ADF: %v", err)
+		return ""
+	}
+	return string(data)
+}
+
+// reportCommentBody renders the report as a Jira comment body: wiki markup for API v2 (Server/Data
+// Center) or an ADF document for v3 (Cloud).
+func reportCommentBody(issues []*IssueData, cfg *ReportConfig, apiVersion string) any {
+	if apiVersion == "3" {
+		return buildADFReport(issues, cfg)
+	}
+	return RenderWikiMarkupReport(issues, cfg)
+}
+
+// jiraCommentMaxChars is Jira's limit on a comment body; longer comments are rejected with a bare 400.
+const jiraCommentMaxChars = 32767
+
+// commentBodyLength measures a comment body like Jira does: characters of wiki markup, or of the ADF JSON.
+func commentBodyLength(body any) int {
+	if s, ok := body.(string); ok {
+		return utf8.RuneCountInString(s)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0
+	}
+	return utf8.RuneCount(data)
+}
+
+// postReportComment posts the report as a comment on cfg.PostCommentKey, or prints the request payload
+// instead with cfg.DryRun. A report over Jira's comment size limit is an error either way.
+func postReportComment(ctx context.Context, client *JiraClient, parentIssues []*IssueData, cfg *ReportConfig) error {
+	body := reportCommentBody(issuesForReport(parentIssues, cfg), cfg, client.APIVersion)
+	if n := commentBodyLength(body); n > jiraCommentMaxChars {
+		return fmt.Errorf("report is %d characters but Jira comments are limited to %d; narrow the query, load fewer child levels or use --confluence-page-id", n, jiraCommentMaxChars)
+	}
+	if cfg.DryRun {
+		payload, err := json.MarshalIndent(map[string]any{"body": body}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("POST %s/rest/api/%s/issue/%s/comment\n%s\n", client.Server, client.APIVersion, cfg.PostCommentKey, payload)
+		return nil
+	}
+	resp, err := client.PostComment(ctx, cfg.PostCommentKey, body)
+	if err != nil {
+		return fmt.Errorf("post comment on %s: %w", cfg.PostCommentKey, err)
+	}
+	fmt.Printf("Posted report as a comment: %s/browse/%s?focusedCommentId=%s\n", client.Server, cfg.PostCommentKey, getString(resp, "id", ""))
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderJiraWikiReport_serverUsesWikiMarkup(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "E-1", URL: "https://jira.corp.example.com/browse/E-1", Summary: "Fix [login] | *now*", Type: "Epic",
+			Status: "in progress", Trending: "at risk", TrendingEmoji: "🟡", Due: "2025-04-01",
+			Comment: IssueComment{Url: "https://jira.corp.example.com/browse/E-1?focusedCommentId=9", Created: "2025-03-01T00:00:00.000+0000"}},
+		{Key: "E-2", URL: "https://jira.corp.example.com/browse/E-2", Summary: "Quiet", Status: "new"},
+	}
+	out := RenderJiraWikiReport(issues, &ReportConfig{Title: "Weekly", JiraServer: "https://jira.corp.example.com"})
+	lines := strings.Split(out, "\n")
+	if !strings.HasPrefix(lines[0], "h3. Weekly @ ") {
+		t.Errorf("heading = %q", lines[0])
+	}
+	if !strings.Contains(out, "||trending||type||status||issue||assignee||due date||last update||comment||") {
+		t.Errorf("missing header row:\n%s", out)
+	}
+	wantRow := `|{color:#FFAB00}🟡 at risk{color}|Epic|in progress|[Fix \[login\] \| \*now\*|https://jira.corp.example.com/browse/E-1]| |2025-04-01|[2025-03-01|https://jira.corp.example.com/browse/E-1?focusedCommentId=9]| |`
+	if !strings.Contains(out, wantRow) {
+		t.Errorf("missing row %q:\n%s", wantRow, out)
+	}
+	if !strings.Contains(out, "| | |new|[Quiet|") {
+		t.Errorf("empty cells should hold a space:\n%s", out)
+	}
+}
+
+func TestRenderJiraWikiReport_cloudUsesADF(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "E-1", URL: "https://acme.atlassian.net/browse/E-1", Summary: "Launch", Type: "Epic", Status: "blocked",
+			Trending: "off track", ParentKey: "I-1"},
+	}
+	out := RenderJiraWikiReport(issues, &ReportConfig{Title: "Weekly", RenderChildren: true, JiraServer: "https://acme.atlassian.net"})
+	var doc adfNode
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("ADF JSON: %v\n%s", err, out)
+	}
+	if doc.Type != "doc" || doc.Version != 1 || len(doc.Content) != 3 {
+		t.Fatalf("doc = %+v", doc)
+	}
+	table := doc.Content[2]
+	if table.Type != "table" || len(table.Content) != 2 || len(table.Content[0].Content) != 9 {
+		t.Fatalf("table should have a header and one row of 9 cells (with parent): %+v", table)
+	}
+	row := table.Content[1].Content
+	status := row[0].Content[0].Content[0]
+	if status.Type != "status" || status.Attrs["color"] != "red" || status.Attrs["text"] != "off track" {
+		t.Errorf("trending cell = %+v", status)
+	}
+	link := row[3].Content[0].Content[0]
+	if link.Text != "Launch" || len(link.Marks) != 1 || link.Marks[0].Attrs["href"] != "https://acme.atlassian.net/browse/E-1" {
+		t.Errorf("issue cell = %+v", link)
+	}
+	if strings.Contains(out, `"text": ""`) {
+		t.Error("ADF must not contain empty text nodes")
+	}
+}
+
+func TestRenderJiraWikiReport_emptyCloudResultUsesADF(t *testing.T) {
+	out := RenderJiraWikiReport(nil, &ReportConfig{Title: "Weekly", JiraServer: "https://acme.atlassian.net/"})
+	var doc adfNode
+	if err := json.Unmarshal([]byte(out), &doc); err != nil || doc.Type != "doc" {
+		t.Errorf("an empty Cloud report should still be ADF: %v\n%s", err, out)
+	}
+}
+
+func TestEscapeJiraWiki(t *testing.T) {
+	for in, want := range map[string]string{
+		"Fix !important! bug":       `Fix \!important\! bug`,
+		"-struck- +under+ ^sup^":    `\-struck\- \+under\+ \^sup\^`,
+		"~sub~ ??cite?? #1 *b* _i_": `\~sub\~ \?\?cite\?\? \#1 \*b\* \_i\_`,
+		"{code} [x|y] a\\b":         `\{code\} \[x\|y\] a\\b`,
+		"  ":                        " ",
+	} {
+		if got := escapeJiraWiki(in); got != want {
+			t.Errorf("escapeJiraWiki(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPostReportComment(t *testing.T) {
+	var posted []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || !strings.HasSuffix(r.URL.Path, "/issue/INIT-1/comment") {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		posted = append(posted, body)
+		json.NewEncoder(w).Encode(map[string]any{"id": "10001"})
+	}))
+	defer ts.Close()
+
+	parents := []*IssueData{{Key: "E-1", URL: ts.URL + "/browse/E-1", Summary: "Epic", Trending: "on track"}}
+	cfg := &ReportConfig{Title: "Weekly", PostCommentKey: "INIT-1"}
+
+	server := testJiraClientForServer(ts)
+	if err := postReportComment(context.Background(), server, parents, cfg); err != nil {
+		t.Fatalf("postReportComment (v2): %v", err)
+	}
+	cloud := testJiraClientForServer(ts)
+	cloud.APIVersion = "3"
+	if err := postReportComment(context.Background(), cloud, parents, cfg); err != nil {
+		t.Fatalf("postReportComment (v3): %v", err)
+	}
+	if len(posted) != 2 {
+		t.Fatalf("posted %d comments, want 2", len(posted))
+	}
+	if wiki, ok := posted[0]["body"].(string); !ok || !strings.Contains(wiki, "||trending||") {
+		t.Errorf("v2 body should be wiki markup: %v", posted[0]["body"])
+	}
+	if doc, ok := posted[1]["body"].(map[string]any); !ok || doc["type"] != "doc" {
+		t.Errorf("v3 body should be an ADF doc: %v", posted[1]["body"])
+	}
+
+	cfg.DryRun = true
+	if err := postReportComment(context.Background(), server, parents, cfg); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(posted) != 2 {
+		t.Error("--dry-run must not post")
+	}
+
+	cfg.DryRun = false
+	var many []*IssueData
+	for i := 0; i < 400; i++ {
+		many = append(many, &IssueData{Key: fmt.Sprintf("E-%d", i), URL: ts.URL + "/browse/E", Summary: strings.Repeat("long summary ", 8)})
+	}
+	err := postReportComment(context.Background(), server, many, cfg)
+	if err == nil || !strings.Contains(err.Error(), "limited to 32767") {
+		t.Errorf("oversized report should fail before posting, got %v", err)
+	}
+	if len(posted) != 2 {
+		t.Error("an oversized report must not be posted")
+	}
+}
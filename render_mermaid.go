@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mermaidGanttTags are the Gantt task tags (Mermaid's built-in task colours) for trending values.
+var mermaidGanttTags = map[string]string{
+	"done":      "done",
+	"at risk":   "active",
+	"off track": "crit",
+}
+
+// mermaidTextReplacer drops characters that end or split a Gantt task name or break a quoted node label.
+var mermaidTextReplacer = strings.NewReplacer(":", " ", ";", " ", "#", " ", `"`, "'", "\n", " ", "`", "'")
+
+// mermaidText makes s safe as Gantt task text or a quoted flowchart label.
+func mermaidText(s string) string {
+	return strings.Join(strings.Fields(mermaidTextReplacer.Replace(s)), " ")
+}
+
+var mermaidIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// mermaidNodeID turns an issue key into a flowchart node ID, e.g. "PROJ-12" -> "PROJ_12".
+func mermaidNodeID(key string) string {
+	return mermaidIDUnsafe.ReplaceAllString(key, "_")
+}
+
+// mermaidTrendingClass returns the classDef name for a trending value, e.g. "on track" -> "on_track".
+func mermaidTrendingClass(trending string) string {
+	return strings.ReplaceAll(badgeClass(trending), "-", "_")
+}
+
+// sortedTrendings orders trending values worst first (like the HTML report), unknown values last by name.
+func sortedTrendings(values map[string]bool) []string {
+	out := make([]string, 0, len(values))
+	for v := range values {
+		out = append(out, v)
+	}
+	rank := func(v string) int {
+		if r, ok := htmlTrendingOrder[v]; ok {
+			return r
+		}
+		return len(htmlTrendingOrder)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ri, rj := rank(out[i]), rank(out[j]); ri != rj {
+			return ri < rj
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
+
+// RenderMermaidReport renders issues as a fenced Mermaid diagram (--mermaid gantt|graph) under a markdown
+// heading, so it can be appended to the same markdown file as other reports.
+func RenderMermaidReport(issues []*IssueData, cfg *ReportConfig) string {
+	issues = filterAndSortIssues(issues, cfg)
+	var diagram string
+	if cfg.MermaidOutput == "graph" {
+		diagram = renderMermaidGraph(issues)
+	} else {
+		diagram = renderMermaidGantt(issues, cfg)
+	}
+	return fmt.Sprintf("### %s @ %s\n\n```mermaid\n%s\n```", escapeMarkdownInline(cfg.Title), time.Now().Format(time.RFC3339), diagram)
+}
+
+// renderMermaidGantt renders a Gantt chart from Created to Due with one section per trending value.
+// Issues without a due date are listed in a comment, since the chart cannot place them.
+func renderMermaidGantt(issues []*IssueData, cfg *ReportConfig) string {
+	lines := []string{"gantt", "    title " + mermaidText(cfg.Title), "    dateFormat YYYY-MM-DD", "    axisFormat %b %d"}
+	sections := map[string][]string{}
+	present := map[string]bool{}
+	var undated []string
+	for _, issue := range issues {
+		due, err := ParseJiraDate(issue.Due)
+		if err != nil {
+			undated = append(undated, issue.Key)
+			continue
+		}
+		start := due
+		if created, err := ParseJiraDate(issue.Created); err == nil && created.Before(due) {
+			start = created
+		}
+		trending := issue.Trending
+		if trending == "" {
+			trending = "unknown"
+		}
+		tags := ""
+		if tag, ok := mermaidGanttTags[trending]; ok {
+			tags = tag + ", "
+		}
+		present[trending] = true
+		sections[trending] = append(sections[trending], fmt.Sprintf("    %s %s :%s%s, %s",
+			issue.Key, mermaidText(issue.Summary), tags, start.Format("2006-01-02"), due.Format("2006-01-02")))
+	}
+	for _, trending := range sortedTrendings(present) {
+		lines = append(lines, fmt.Sprintf("    section %s %s", activeStatusMap.trendingEmoji(trending), trending))
+		lines = append(lines, sections[trending]...)
+	}
+	if len(undated) > 0 {
+		lines = append(lines, fmt.Sprintf("    %%%% no due date: %s", strings.Join(undated, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderMermaidGraph renders the parent -> child tree from IssueData.Children as a flowchart, with nodes
+// coloured by trending and linked to Jira. An issue reached through several parents is drawn once.
+func renderMermaidGraph(issues []*IssueData) string {
+	lines := []string{"flowchart LR"}
+	var edges, clicks []string
+	seen := map[string]bool{}
+	present := map[string]bool{}
+	var visit func(issue *IssueData)
+	visit = func(issue *IssueData) {
+		id := mermaidNodeID(issue.Key)
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		trending := issue.Trending
+		if trending == "" {
+			trending = "unknown"
+		}
+		present[trending] = true
+		label := strings.TrimSpace(fmt.Sprintf("%s %s: %s", issue.TrendingEmoji, issue.Key, mermaidText(issue.Summary)))
+		lines = append(lines, fmt.Sprintf(`    %s["%s"]:::%s`, id, label, mermaidTrendingClass(trending)))
+		if issue.URL != "" {
+			clicks = append(clicks, fmt.Sprintf(`    click %s "%s" _blank`, id, strings.ReplaceAll(issue.URL, `"`, "%22")))
+		}
+		for _, child := range filterAndSortIssues(issue.Children, &ReportConfig{}) {
+			edges = append(edges, fmt.Sprintf("    %s --> %s", id, mermaidNodeID(child.Key)))
+			visit(child)
+		}
+	}
+	for _, issue := range issues {
+		visit(issue)
+	}
+	lines = append(lines, edges...)
+	lines = append(lines, clicks...)
+	for _, trending := range sortedTrendings(present) {
+		style := "fill:#DFE1E6,stroke:#172B4D,color:#172B4D"
+		if colour, ok := jiraWikiColours[trending]; ok {
+			style = fmt.Sprintf("fill:%s,stroke:#172B4D,color:#FFFFFF", colour)
+		}
+		lines = append(lines, fmt.Sprintf("    classDef %s %s", mermaidTrendingClass(trending), style))
+	}
+	return strings.Join(lines, "\n")
+}
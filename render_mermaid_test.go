@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMermaidReport_gantt(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "E-1", Summary: "Launch: phase #1", Status: "in progress", Trending: "off track", TrendingEmoji: "🔴",
+			Created: "2025-01-10T09:00:00.000+0000", Due: "2025-03-01"},
+		{Key: "E-2", Summary: "Polish", Status: "in progress", Trending: "on track", TrendingEmoji: "🟢",
+			Created: "2025-02-01T09:00:00.000+0000", Due: "2025-01-15"},
+		{Key: "E-3", Summary: "Someday", Status: "new", Trending: "not started"},
+	}
+	out := RenderMermaidReport(issues, &ReportConfig{Title: "Roadmap", MermaidOutput: "gantt"})
+	if !strings.Contains(out, "```mermaid\ngantt\n    title Roadmap\n    dateFormat YYYY-MM-DD") || !strings.HasSuffix(out, "\n```") {
+		t.Fatalf("not a fenced gantt chart:\n%s", out)
+	}
+	for _, want := range []string{
+		"    section 🔴 off track\n    E-1 Launch phase 1 :crit, 2025-01-10, 2025-03-01",
+		"    section 🟢 on track\n    E-2 Polish :2025-01-15, 2025-01-15", // due before created: a zero-length bar at due
+		"    %% no due date: E-3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "off track") > strings.Index(out, "on track") {
+		t.Error("sections should list off track before on track")
+	}
+}
+
+func TestRenderMermaidReport_graph(t *testing.T) {
+	shared := &IssueData{Key: "S-9", Summary: `Shared "dep"`, Status: "closed", Trending: "done", TrendingEmoji: "🟣"}
+	issues := []*IssueData{
+		{Key: "E-1", URL: "https://jira/browse/E-1", Summary: "Epic one", Status: "in progress", Trending: "at risk", TrendingEmoji: "🟡",
+			Children: []*IssueData{{Key: "S-1", Summary: "Story", Status: "new"}, shared}},
+		{Key: "E-2", Summary: "Epic two", Status: "in progress", Trending: "on track", TrendingEmoji: "🟢", Children: []*IssueData{shared}},
+	}
+	out := RenderMermaidReport(issues, &ReportConfig{Title: "Tree", MermaidOutput: "graph"})
+	for _, want := range []string{
+		"```mermaid\nflowchart LR\n",
+		`    E_1["🟡 E-1: Epic one"]:::at_risk`,
+		`    S_1["S-1: Story"]:::unknown`,
+		`    S_9["🟣 S-9: Shared 'dep'"]:::done`,
+		"    E_1 --> S_1",
+		"    E_1 --> S_9",
+		"    E_2 --> S_9",
+		`    click E_1 "https://jira/browse/E-1" _blank`,
+		"    classDef at_risk fill:#FFAB00",
+		"    classDef unknown fill:#DFE1E6",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, `S_9["`); n != 1 {
+		t.Errorf("shared child drawn %d times, want once", n)
+	}
+}
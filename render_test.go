@@ -194,6 +194,24 @@ func TestRenderMarkdownStatusSummary(t *testing.T) {
 	}
 }
 
+func TestRenderMarkdownStatusSummary_usesJiraStatusNames(t *testing.T) {
+	issues := []*IssueData{
+		{Key: "A-1", Status: "in progress", StatusName: "In Review"},
+		{Key: "A-2", Status: "in progress", StatusName: "QA"},
+		{Key: "A-3", Status: "resolved"}, // cached before StatusName existed
+	}
+	out := RenderMarkdownStatusSummary(issues, &ReportConfig{Title: "T"})
+	for _, want := range []string{"| in review | 1 |", "| qa | 1 |", "| resolved | 1 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in summary: %s", want, out)
+		}
+	}
+	md := RenderMarkdownReport(issues[:1], &ReportConfig{Title: "T"})
+	if !strings.Contains(md, "| in review |") {
+		t.Errorf("markdown should show the Jira status name: %s", md)
+	}
+}
+
 func TestRenderMarkdownStatusSummary_empty(t *testing.T) {
 	out := RenderMarkdownStatusSummary(nil, &ReportConfig{Title: "Empty"})
 	if !strings.Contains(out, "* total issues: 0*") || !strings.Contains(out, "No issues") {
@@ -411,6 +429,18 @@ func TestRenderCSVReport(t *testing.T) {
 	}
 }
 
+func TestRenderCSVReport_extraFields(t *testing.T) {
+	issues := []*IssueData{{Key: "A-1", Summary: "First", Extra: map[string]string{"Story Points": "3", "labels": "ops"}}}
+	out := RenderCSVReport(issues, &ReportConfig{ExtraFields: []string{"Story Points", "labels"}})
+	lines := strings.Split(out, "\n")
+	if !strings.HasSuffix(lines[0], "trending_comment🐱Story Points🐱labels") {
+		t.Errorf("header should end with the --field columns: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "🐱3🐱ops") {
+		t.Errorf("row should end with the --field values: %q", lines[1])
+	}
+}
+
 func TestRenderCSVReport_noParentColumns(t *testing.T) {
 	issues := []*IssueData{
 		{
@@ -844,3 +874,71 @@ func TestRenderReport_renderChildren_usesChildRows(t *testing.T) {
 		t.Errorf("expected child issue in output, got: %q", out)
 	}
 }
+
+func TestRenderReport_renderChildren_showsParent(t *testing.T) {
+	child := &IssueData{Key: "C-1", Summary: "Child issue", Status: "new", URL: "https://jira.example.com/browse/C-1", ParentKey: "P-1"}
+	cfg := &ReportConfig{Title: "T", RenderChildren: true}
+
+	md := RenderMarkdownReport([]*IssueData{child}, cfg)
+	if !strings.Contains(md, "| issue | parent |") || !strings.Contains(md, "[P-1](https://jira.example.com/browse/P-1)") {
+		t.Errorf("markdown child rows should link the parent:\n%s", md)
+	}
+	if out := RenderSimpleReport([]*IssueData{child}, cfg); !strings.Contains(out, "C-1 (parent P-1)") {
+		t.Errorf("simple child rows should name the parent: %q", out)
+	}
+	if md := RenderMarkdownReport([]*IssueData{child}, &ReportConfig{Title: "T"}); strings.Contains(md, "parent") {
+		t.Errorf("parent column should only appear when rendering children:\n%s", md)
+	}
+}
+
+func TestRenderJSONReport_truncation(t *testing.T) {
+	issues := []*IssueData{{Key: "A-1", Summary: "First"}}
+	out := RenderJSONReport(issues, &ReportConfig{Truncated: true, MaxResults: 1})
+	var decoded []*IssueData
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("truncated output should still be an array without --json-envelope: %v\n%s", err, out)
+	}
+	if len(decoded) != 1 || decoded[0].Key != "A-1" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	for _, truncated := range []bool{true, false} {
+		out := RenderJSONReport(issues, &ReportConfig{JSONEnvelope: true, Truncated: truncated, MaxResults: 1})
+		var envelope struct {
+			Truncated  *bool        `json:"truncated"`
+			MaxResults int          `json:"max_results"`
+			Issues     []*IssueData `json:"issues"`
+		}
+		if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+			t.Fatalf("--json-envelope output should be an object: %v\n%s", err, out)
+		}
+		if envelope.Truncated == nil || *envelope.Truncated != truncated || envelope.MaxResults != 1 || len(envelope.Issues) != 1 {
+			t.Errorf("truncated=%t: envelope = %s", truncated, out)
+		}
+	}
+}
+
+func TestRenderSimpleAndSlackReport_truncationNote(t *testing.T) {
+	issues := []*IssueData{{Key: "A-1", Summary: "First", Status: "new"}}
+	cfg := &ReportConfig{Truncated: true, MaxResults: 1}
+	if out := RenderSimpleReport(issues, cfg); !strings.HasPrefix(out, "⚠️ truncated: at least one query returned more than 1 issues") {
+		t.Errorf("simple report should start with the truncation note:\n%s", out)
+	}
+	if out := RenderSlackReport(issues, cfg); !strings.HasPrefix(out, "⚠️ *truncated:* at least one query") {
+		t.Errorf("slack report should start with the truncation note:\n%s", out)
+	}
+	if out := RenderSimpleReport(issues, &ReportConfig{}); strings.Contains(out, "truncated") {
+		t.Errorf("untruncated report should not mention truncation: %s", out)
+	}
+}
+
+func TestRenderMarkdownReport_truncationNote(t *testing.T) {
+	issues := []*IssueData{{Key: "A-1", Summary: "First", Status: "new"}}
+	if out := RenderMarkdownReport(issues, &ReportConfig{Title: "T"}); strings.Contains(out, "truncated") {
+		t.Errorf("untruncated report should not mention truncation: %s", out)
+	}
+	out := RenderMarkdownReport(issues, &ReportConfig{Title: "T", Truncated: true, MaxResults: 1000})
+	if !strings.Contains(out, "**truncated:**") || !strings.Contains(out, "1000") {
+		t.Errorf("expected truncation note in header: %s", out)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// treeRow is one line of a --tree report: an issue and how deep it sits under its top-level parent.
+type treeRow struct {
+	Issue *IssueData
+	Depth int
+}
+
+// treeRows lists the (already filtered and sorted) issues as rows. With tree, each is followed by its
+// loaded descendants, depth first and sorted like parents plus sortKeys (--sort); an issue already on the path
+// above is not repeated.
+func treeRows(issues []*IssueData, tree bool, sortKeys []SortKey) []treeRow {
+	rows := make([]treeRow, 0, len(issues))
+	if !tree {
+		for _, issue := range issues {
+			rows = append(rows, treeRow{Issue: issue})
+		}
+		return rows
+	}
+	onPath := map[string]bool{}
+	var walk func(issue *IssueData, depth int)
+	walk = func(issue *IssueData, depth int) {
+		if onPath[issue.Key] {
+			return
+		}
+		onPath[issue.Key] = true
+		rows = append(rows, treeRow{Issue: issue, Depth: depth})
+		for _, child := range filterAndSortIssues(issue.Children, &ReportConfig{SortKeys: sortKeys}) {
+			walk(child, depth+1)
+		}
+		onPath[issue.Key] = false
+	}
+	for _, issue := range issues {
+		walk(issue, 0)
+	}
+	return rows
+}
+
+// trendingRollup summarizes an issue's direct children, e.g. "4/7 done, 1 at risk"; "" without children.
+func trendingRollup(issue *IssueData) string {
+	if len(issue.Children) == 0 {
+		return ""
+	}
+	counts := map[string]int{}
+	for _, child := range issue.Children {
+		counts[child.Trending]++
+	}
+	parts := []string{fmt.Sprintf("%d/%d done", counts["done"], len(issue.Children))}
+	for _, trending := range []string{"off track", "at risk"} {
+		if counts[trending] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[trending], trending))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// treeIndent prefixes a key or link with its depth in the tree, using indent per level.
+func treeIndent(s string, depth int, indent string) string {
+	if depth == 0 {
+		return s
+	}
+	return strings.Repeat(indent, depth-1) + "└ " + s
+}
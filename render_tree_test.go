@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func treeTestIssues() []*IssueData {
+	sub := &IssueData{Key: "T-1", Summary: "Sub-task", Status: "closed", Trending: "done", TrendingEmoji: "🟣"}
+	return []*IssueData{
+		{Key: "E-1", URL: "https://jira/browse/E-1", Summary: "Epic", Status: "in progress", Trending: "at risk", TrendingEmoji: "🟡",
+			Children: []*IssueData{
+				{Key: "S-1", URL: "https://jira/browse/S-1", Summary: "Done story", Status: "closed", Trending: "done", TrendingEmoji: "🟣"},
+				{Key: "S-2", URL: "https://jira/browse/S-2", Summary: "Risky story", Status: "in progress", Trending: "at risk", TrendingEmoji: "🟡",
+					Children: []*IssueData{sub}},
+				{Key: "S-3", URL: "https://jira/browse/S-3", Summary: "New story", Status: "new", Trending: "not started", TrendingEmoji: "⚪"},
+			}},
+		{Key: "E-2", URL: "https://jira/browse/E-2", Summary: "Quiet epic", Status: "new", Trending: "not started"},
+	}
+}
+
+func TestTreeRows(t *testing.T) {
+	issues := treeTestIssues()
+	issues[0].Children[1].Children[0].Children = []*IssueData{issues[0]} // a link cycle back to the top
+	var got []string
+	for _, row := range treeRows(issues, true, nil) {
+		got = append(got, strings.Repeat(">", row.Depth)+row.Issue.Key)
+	}
+	if want := "E-1 >S-1 >S-2 >>T-1 >S-3 E-2"; strings.Join(got, " ") != want {
+		t.Errorf("rows = %q, want %q", strings.Join(got, " "), want)
+	}
+	if rows := treeRows(issues, false, nil); len(rows) != 2 {
+		t.Errorf("without tree, rows = %d, want the 2 parents", len(rows))
+	}
+	got = nil
+	for _, row := range treeRows(treeTestIssues()[:1], true, []SortKey{{Field: "key", Desc: true}}) {
+		got = append(got, row.Issue.Key)
+	}
+	if want := "E-1 S-3 S-2 T-1 S-1"; strings.Join(got, " ") != want {
+		t.Errorf("children should follow the sort keys: rows = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestTrendingRollup(t *testing.T) {
+	issues := treeTestIssues()
+	if got := trendingRollup(issues[0]); got != "1/3 done, 1 at risk" {
+		t.Errorf("rollup = %q", got)
+	}
+	if got := trendingRollup(issues[1]); got != "" {
+		t.Errorf("rollup without children = %q, want empty", got)
+	}
+}
+
+func TestRenderReports_tree(t *testing.T) {
+	cfg := &ReportConfig{Title: "Tree", TreeOutput: true}
+
+	md := RenderMarkdownReport(treeTestIssues(), cfg)
+	for _, want := range []string{
+		"| trending | type | status | issue | rollup | assignee |",
+		"[Epic](https://jira/browse/E-1) | 1/3 done, 1 at risk |",
+		"| └ [Risky story](https://jira/browse/S-2) | 1/1 done |",
+		"| &nbsp;&nbsp;&nbsp;└ [Sub-task]",
+		"* row count: 2",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	simple := RenderSimpleReport(treeTestIssues(), cfg)
+	lines := strings.Split(simple, "\n")
+	if len(lines) != 6 {
+		t.Fatalf("simple should have 6 rows:\n%s", simple)
+	}
+	if !strings.Contains(lines[0], "E-1") || !strings.Contains(lines[0], "1/3 done, 1 at risk") {
+		t.Errorf("parent row = %q", lines[0])
+	}
+	if !strings.Contains(lines[3], "  └ T-1") {
+		t.Errorf("grandchild row should be indented twice: %q", lines[3])
+	}
+
+	html := RenderHTMLReport(treeTestIssues(), cfg)
+	if !strings.Contains(html, "<details open><summary>3 children · 1/3 done, 1 at risk</summary>") {
+		t.Errorf("HTML tree should expand children with a rollup:\n%s", html)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -38,6 +39,7 @@ var reportCache = &filecache.Store{
 // issueCacheFile is the on-disk JSON envelope for Jira issue snapshots.
 type issueCacheFile struct {
 	ParentIssues []*IssueData `json:"parent_issues"`
+	Truncated    bool         `json:"truncated,omitempty"`
 }
 
 // CacheDir returns the cache directory (~/.snippets/cache by default).
@@ -46,7 +48,7 @@ func CacheDir() (string, error) {
 }
 
 // CacheKey returns a deterministic filename-safe key for the query (JQL or sorted issue keys),
-// whether child issues were loaded, and due-date / trending field configuration (must match FetchReportIssues).
+// whether (and how deep and via which links/fields) child issues were loaded, the per-search result cap, whether changelogs were loaded, due-date / trending field configuration, and the status map and trending rules files (must match FetchReportIssues).
 func CacheKey(cfg *ReportConfig, issueKeys []string) string {
 	if cfg == nil {
 		cfg = &ReportConfig{}
@@ -61,11 +63,39 @@ func CacheKey(cfg *ReportConfig, issueKeys []string) string {
 		parts = []string{"keys:", strings.Join(k, ",")}
 	}
 	if cfg.IncludeChildren {
-		parts = append(parts, "|children:1")
+		parts = append(parts, "|children:1", fmt.Sprintf("|depth:%d", cfg.childDepth()))
+		if cfg.ChildLinkTypes != nil {
+			parts = append(parts, "|links:", strings.Join(cfg.ChildLinkTypes, ","))
+		}
+		if cfg.HierarchyFieldNames != nil {
+			parts = append(parts, "|hierarchy:", strings.Join(cfg.HierarchyFieldNames, ","))
+		}
+		if cfg.NoChildIssuesOf {
+			parts = append(parts, "|childIssuesOf:0")
+		}
 	} else {
 		parts = append(parts, "|children:0")
 	}
+	parts = append(parts, fmt.Sprintf("|max:%d", max(cfg.MaxResults, 0)))
+	if cfg.LoadChangelog {
+		parts = append(parts, "|changelog:1")
+	}
+	if fields := cfg.extraFieldNames(); len(fields) > 0 {
+		parts = append(parts, "|fields:", strings.Join(fields, ","))
+	}
+	if cfg.LoadIssueLinks {
+		parts = append(parts, "|issueLinks:1")
+	}
 	parts = append(parts, "|dueField:", strings.TrimSpace(cfg.DueDateFieldName), "|trendField:", strings.TrimSpace(cfg.TrendingStatusFieldName))
+	if cfg.WriteTrending {
+		parts = append(parts, "|writeTrending:1")
+	}
+	if cfg.StatusMapDigest != "" {
+		parts = append(parts, "|statusMap:", cfg.StatusMapDigest)
+	}
+	if cfg.TrendingRulesDigest != "" {
+		parts = append(parts, "|trendingRules:", cfg.TrendingRulesDigest)
+	}
 	return filecache.KeyFromString(strings.Join(parts, ""))
 }
 
@@ -75,16 +105,24 @@ func ClearCache() error {
 }
 
 func readIssueCache(path string) ([]*IssueData, error) {
-	ent, err := filecache.ReadJSON[issueCacheFile](path)
+	ent, err := readIssueCacheFile(path)
 	if err != nil {
 		return nil, err
 	}
 	return ent.ParentIssues, nil
 }
 
+func readIssueCacheFile(path string) (issueCacheFile, error) {
+	return filecache.ReadJSON[issueCacheFile](path)
+}
+
 func writeIssueCache(path string, parentIssues []*IssueData) error {
-	if parentIssues == nil {
-		parentIssues = []*IssueData{}
+	return writeIssueCacheFile(path, issueCacheFile{ParentIssues: parentIssues})
+}
+
+func writeIssueCacheFile(path string, ent issueCacheFile) error {
+	if ent.ParentIssues == nil {
+		ent.ParentIssues = []*IssueData{}
 	}
-	return filecache.WriteJSON(path, issueCacheFile{ParentIssues: parentIssues})
+	return filecache.WriteJSON(path, ent)
 }
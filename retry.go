@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retry defaults for doRequest. Retries are shared across all goroutines of a run via RetryBudget.
+const (
+	defaultMaxRetries  = 5
+	defaultRetryBudget = 50
+	retryBaseDelay     = 500 * time.Millisecond
+	retryMaxDelay      = 60 * time.Second
+)
+
+// retrySleep waits between attempts, returning early with ctx.Err() on cancellation; tests may replace it.
+var retrySleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isRetryableStatus reports whether an HTTP status is worth retrying (rate limit or transient server error).
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfterFromHeaders returns the server-requested wait from Retry-After (seconds or HTTP date),
+// or from X-RateLimit-Reset / X-RateLimit-Interval-Seconds when X-RateLimit-Remaining is exhausted.
+// The second return is false when the response carries no usable hint.
+func retryAfterFromHeaders(h http.Header, now time.Time) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+	}
+	if strings.TrimSpace(h.Get("X-RateLimit-Remaining")) != "0" {
+		return 0, false
+	}
+	// Jira Cloud sends an ISO 8601 reset time; some proxies send epoch seconds.
+	if v := strings.TrimSpace(h.Get("X-RateLimit-Reset")); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+		if t, err := time.Parse("2006-01-02T15:04Z", v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return max(time.Unix(epoch, 0).Sub(now), 0), true
+		}
+	}
+	// Jira Data Center token bucket: the bucket refills every interval.
+	if v := strings.TrimSpace(h.Get("X-RateLimit-Interval-Seconds")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// backoffDelay returns an exponential delay for the given attempt (0-based) with equal jitter:
+// half of the step is fixed and the other half is random, capped at retryMaxDelay.
+func backoffDelay(attempt int) time.Duration {
+	d := retryBaseDelay << min(attempt, 16)
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func (c *JiraClient) maxRetries() int {
+	if c.MaxRetries < 0 {
+		return 0
+	}
+	if c.MaxRetries == 0 {
+		return defaultMaxRetries
+	}
+	return c.MaxRetries
+}
+
+func (c *JiraClient) retryBudget() int64 {
+	if c.RetryBudget < 1 {
+		return defaultRetryBudget
+	}
+	return int64(c.RetryBudget)
+}
+
+// takeRetry consumes one retry from the per-run budget; false once the budget is spent.
+func (c *JiraClient) takeRetry() bool {
+	return c.retriesUsed.Add(1) <= c.retryBudget()
+}
+
+// nextRetryDelay decides whether attempt (0-based) may be retried and how long to wait first.
+// header is nil for network errors. Server hints longer than retryMaxDelay are not retried.
+func (c *JiraClient) nextRetryDelay(attempt int, header http.Header) (time.Duration, bool) {
+	if attempt >= c.maxRetries() {
+		return 0, false
+	}
+	wait, hinted := retryAfterFromHeaders(header, time.Now())
+	if hinted && wait > retryMaxDelay {
+		logWarning("Server asked to wait %s before retrying; giving up", wait.Round(time.Second))
+		return 0, false
+	}
+	if !hinted {
+		wait = backoffDelay(attempt)
+	}
+	if !c.takeRetry() {
+		logWarning("Retry budget of %d exhausted; not retrying", c.retryBudget())
+		return 0, false
+	}
+	return wait, true
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJiraClientForServer builds a client pointed at an httptest server (no connection test).
+func testJiraClientForServer(ts *httptest.Server) *JiraClient {
+	return &JiraClient{Server: ts.URL, APIVersion: "2", HTTPClient: ts.Client()}
+}
+
+// noRetrySleep replaces retrySleep for the duration of a test and records requested waits.
+func noRetrySleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	old := retrySleep
+	retrySleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	t.Cleanup(func() { retrySleep = old })
+	return &waits
+}
+
+func TestRetryAfterFromHeaders(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+		wantOK  bool
+	}{
+		{"none", nil, 0, false},
+		{"seconds", map[string]string{"Retry-After": "7"}, 7 * time.Second, true},
+		{"http date", map[string]string{"Retry-After": now.Add(3 * time.Second).Format(http.TimeFormat)}, 3 * time.Second, true},
+		{"reset ignored while remaining", map[string]string{"X-RateLimit-Remaining": "5", "X-RateLimit-Reset": "2025-03-01T12:00:10Z"}, 0, false},
+		{"cloud reset", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "2025-03-01T12:00:10Z"}, 10 * time.Second, true},
+		{"epoch reset", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1740830405"}, 5 * time.Second, true},
+		{"dc interval", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Interval-Seconds": "2"}, 2 * time.Second, true},
+		{"reset in past", map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "2025-03-01T11:00:00Z"}, 0, true},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		for k, v := range tt.headers {
+			h.Set(k, v)
+		}
+		got, ok := retryAfterFromHeaders(h, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: got (%s, %v), want (%s, %v)", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestBackoffDelay_jitterBounds(t *testing.T) {
+	for attempt := 0; attempt < 20; attempt++ {
+		step := retryBaseDelay << min(attempt, 16)
+		if step > retryMaxDelay {
+			step = retryMaxDelay
+		}
+		for i := 0; i < 20; i++ {
+			d := backoffDelay(attempt)
+			if d < step/2 || d > step {
+				t.Fatalf("attempt %d: delay %s outside [%s, %s]", attempt, d, step/2, step)
+			}
+		}
+	}
+}
+
+func TestDoRequest_retriesRateLimitWithRetryAfter(t *testing.T) {
+	waits := noRetrySleep(t)
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+
+	body, err := testJiraClientForServer(ts).doRequest(context.Background(), "GET", "myself", nil, nil)
+	if err != nil {
+		t.Fatalf("doRequest: %v", err)
+	}
+	if string(body) != `{"ok":true}` {
+		t.Errorf("body = %s", body)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	if len(*waits) != 1 || (*waits)[0] != 2*time.Second {
+		t.Errorf("waits = %v, want [2s]", *waits)
+	}
+}
+
+func TestDoRequest_retriesServerErrorsWithBackoff(t *testing.T) {
+	waits := noRetrySleep(t)
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	if _, err := testJiraClientForServer(ts).doRequest(context.Background(), "GET", "search", nil, nil); err != nil {
+		t.Fatalf("doRequest: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("waits = %v, want 2 backoffs", *waits)
+	}
+	if (*waits)[0] < retryBaseDelay/2 || (*waits)[0] > retryBaseDelay {
+		t.Errorf("first backoff %s outside jitter range", (*waits)[0])
+	}
+}
+
+func TestDoRequest_doesNotRetryClientErrors(t *testing.T) {
+	waits := noRetrySleep(t)
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	if _, err := testJiraClientForServer(ts).doRequest(context.Background(), "GET", "search", nil, nil); err == nil {
+		t.Fatal("expected error for 400")
+	}
+	if calls.Load() != 1 || len(*waits) != 0 {
+		t.Errorf("calls = %d waits = %v, want a single attempt", calls.Load(), *waits)
+	}
+}
+
+func TestDoRequest_retryBudgetSharedAcrossRequests(t *testing.T) {
+	noRetrySleep(t)
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	c.RetryBudget = 3
+	for i := 0; i < 2; i++ {
+		if _, err := c.doRequest(context.Background(), "GET", "search", nil, nil); err == nil {
+			t.Fatal("expected error once retries are exhausted")
+		}
+	}
+	// 2 initial attempts + 3 budgeted retries in total.
+	if calls.Load() != 5 {
+		t.Errorf("calls = %d, want 5", calls.Load())
+	}
+}
+
+func TestDoRequest_giveUpOnLongRetryAfter(t *testing.T) {
+	waits := noRetrySleep(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	if _, err := testJiraClientForServer(ts).doRequest(context.Background(), "GET", "search", nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(*waits) != 0 {
+		t.Errorf("should not wait an hour: waits = %v", *waits)
+	}
+}
+
+func TestDoRequest_cancelledContextStopsRetries(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		cancel() // the client gives up while waiting to retry
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	_, err := testJiraClientForServer(ts).doRequest(ctx, "GET", "search", nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestRetrySleep_returnsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := retrySleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("retrySleep should return immediately once ctx is done")
+	}
+}
+
+func TestDoRequest_postRetriesOnlyRateLimits(t *testing.T) {
+	noRetrySleep(t)
+	var calls atomic.Int32
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	c := testJiraClientForServer(ts)
+	if _, err := c.doRequest(context.Background(), "POST", "issue/A-1/comment", nil, []byte(`{"body":"hi"}`)); err == nil {
+		t.Fatal("a 5xx on POST may have been applied; it should not be retried")
+	}
+	if calls.Load() != 2 || bodies[0] != `{"body":"hi"}` || bodies[1] != bodies[0] {
+		t.Errorf("calls = %d bodies = %q, want the 429 retried with the same body", calls.Load(), bodies)
+	}
+}
@@ -0,0 +1,460 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zachariahcox/snippets/filecache"
+)
+
+// snapshotTimeFormat names snapshot files; it sorts chronologically as a string.
+const snapshotTimeFormat = "20060102T150405Z"
+
+// Snapshot retention (see pruneSnapshots): older snapshots, and all but the newest per query, are deleted.
+const (
+	snapshotMaxAge      = 180 * 24 * time.Hour
+	snapshotMaxPerQuery = 100
+)
+
+// snapshotDirFn resolves the snapshot root, ~/.snippets/snapshots beside the report cache (so tests that
+// redirect reportCacheDirFn redirect snapshots too). --clear-cache keeps snapshots; pruneSnapshots expires them.
+var snapshotDirFn = func() (string, error) {
+	cacheDir, err := reportCacheDirFn()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cacheDir), "snapshots"), nil
+}
+
+// snapshotFile is one saved FetchReportIssues result.
+type snapshotFile struct {
+	TakenAt      time.Time    `json:"taken_at"`
+	Query        string       `json:"query"`
+	ParentIssues []*IssueData `json:"parent_issues"`
+}
+
+// snapshotQuery identifies what a snapshot is of: the JQL or the sorted issue keys, plus how many child
+// levels were loaded (--children, --depth, --render-children and --tree), since that changes which issues
+// a snapshot holds. Unlike CacheKey it ignores rendering and field configuration, so runs of the same query
+// diff against each other.
+func snapshotQuery(cfg *ReportConfig, issueKeys []string) string {
+	var query string
+	if cfg != nil && cfg.JQLQuery != "" {
+		query = "jql:" + cfg.JQLQuery
+	} else {
+		k := append([]string(nil), issueKeys...)
+		sort.Strings(k)
+		query = "keys:" + strings.Join(k, ",")
+	}
+	if depth := cfg.childDepth(); depth > 0 {
+		query += fmt.Sprintf("|depth:%d", depth)
+	}
+	return query
+}
+
+// snapshotQueryDir is the directory holding every snapshot of one query.
+func snapshotQueryDir(query string) (string, error) {
+	root, err := snapshotDirFn()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, filecache.KeyFromString(query)), nil
+}
+
+// saveSnapshot writes parentIssues as <snapshot dir>/<query key>/<taken at>.json.
+func saveSnapshot(cfg *ReportConfig, issueKeys []string, parentIssues []*IssueData, takenAt time.Time) (string, error) {
+	query := snapshotQuery(cfg, issueKeys)
+	dir, err := snapshotQueryDir(query)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	takenAt = takenAt.UTC().Truncate(time.Second)
+	path := filepath.Join(dir, takenAt.Format(snapshotTimeFormat)+".json")
+	if parentIssues == nil {
+		parentIssues = []*IssueData{}
+	}
+	return path, filecache.WriteJSON(path, snapshotFile{TakenAt: takenAt, Query: query, ParentIssues: parentIssues})
+}
+
+// pruneSnapshots deletes snapshots taken before now - snapshotMaxAge and all but the newest
+// snapshotMaxPerQuery of each query, then removes query directories left empty.
+func pruneSnapshots(now time.Time) error {
+	root, err := snapshotDirFn()
+	if err != nil {
+		return err
+	}
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	cutoff := now.Add(-snapshotMaxAge).UTC().Format(snapshotTimeFormat)
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, d.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			logDebug("Skipping snapshot dir %s: %v", dir, err)
+			continue
+		}
+		var names []string
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(names))) // newest first
+		kept := 0
+		for _, name := range names {
+			if kept < snapshotMaxPerQuery && strings.TrimSuffix(name, ".json") >= cutoff {
+				kept++
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				logWarning("Failed to prune snapshot %s: %v", filepath.Join(dir, name), err)
+			} else {
+				logDebug("Pruned old snapshot: %s", filepath.Join(d.Name(), name))
+			}
+		}
+		if kept == 0 && len(names) == len(entries) {
+			_ = os.Remove(dir) // fails harmlessly if anything else is left in it
+		}
+	}
+	return nil
+}
+
+// listSnapshots returns the times of every snapshot of the query, oldest first.
+func listSnapshots(cfg *ReportConfig, issueKeys []string) ([]time.Time, error) {
+	dir, err := snapshotQueryDir(snapshotQuery(cfg, issueKeys))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var times []time.Time
+	for _, e := range entries {
+		stem, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		if t, err := time.Parse(snapshotTimeFormat, stem); err == nil {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
+
+// findSnapshot loads the diff baseline for --since-snapshot: "last" (or empty) is the newest snapshot
+// taken before now; YYYY-MM-DD or N (days ago, as for --since) is the newest snapshot taken on or before that day.
+func findSnapshot(cfg *ReportConfig, issueKeys []string, since string, now time.Time) (snapshotFile, error) {
+	times, err := listSnapshots(cfg, issueKeys)
+	if err != nil {
+		return snapshotFile{}, err
+	}
+	cutoff := now
+	if s := strings.TrimSpace(since); s != "" && s != "last" {
+		day, err := ParseSince(s, now)
+		if err != nil {
+			return snapshotFile{}, fmt.Errorf("invalid --since-snapshot %q: use last, YYYY-MM-DD, or number of days", since)
+		}
+		y, m, d := day.Date()
+		cutoff = time.Date(y, m, d, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	}
+	for i := len(times) - 1; i >= 0; i-- {
+		if times[i].Before(cutoff) {
+			dir, err := snapshotQueryDir(snapshotQuery(cfg, issueKeys))
+			if err != nil {
+				return snapshotFile{}, err
+			}
+			return filecache.ReadJSON[snapshotFile](filepath.Join(dir, times[i].Format(snapshotTimeFormat)+".json"))
+		}
+	}
+	return snapshotFile{}, fmt.Errorf("no snapshot of this query taken before %s (snapshots are saved whenever a report is fetched from Jira)", cutoff.Format("2006-01-02 15:04"))
+}
+
+// IssueRef names an issue in a diff.
+type IssueRef struct {
+	Key      string `json:"key"`
+	Summary  string `json:"summary"`
+	URL      string `json:"url"`
+	Trending string `json:"trending"`
+	Status   string `json:"status"`
+}
+
+// Change is a field that moved from one value to another between snapshots.
+type Change struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IssueChange lists what changed on an issue present in both snapshots; nil fields did not change.
+type IssueChange struct {
+	IssueRef
+	Trending   *Change       `json:"trending_change,omitempty"`
+	Status     *Change       `json:"status_change,omitempty"`
+	Due        *Change       `json:"due_change,omitempty"`
+	NewComment *IssueComment `json:"new_comment,omitempty"` // latest comment, when added since the baseline
+}
+
+// SnapshotDiff is what changed between a baseline snapshot and the current run (parents and loaded children).
+type SnapshotDiff struct {
+	Since   time.Time     `json:"since"`
+	Until   time.Time     `json:"until"`
+	Added   []IssueRef    `json:"added"`
+	Removed []IssueRef    `json:"removed"`
+	Changed []IssueChange `json:"changed"`
+}
+
+func issueRef(issue *IssueData) IssueRef {
+	return IssueRef{Key: issue.Key, Summary: issue.Summary, URL: issue.URL, Trending: issue.Trending, Status: statusForDisplay(issue)}
+}
+
+// diffSnapshots compares two sets of issues by key.
+func diffSnapshots(base snapshotFile, current []*IssueData, until time.Time) SnapshotDiff {
+	d := SnapshotDiff{Since: base.TakenAt, Until: until.UTC(), Added: []IssueRef{}, Removed: []IssueRef{}, Changed: []IssueChange{}}
+	before := make(map[string]*IssueData)
+	for _, issue := range flattenIssues(base.ParentIssues) {
+		before[issue.Key] = issue
+	}
+	seen := make(map[string]bool)
+	for _, issue := range flattenIssues(current) {
+		if seen[issue.Key] {
+			continue
+		}
+		seen[issue.Key] = true
+		old, ok := before[issue.Key]
+		if !ok {
+			d.Added = append(d.Added, issueRef(issue))
+			continue
+		}
+		ch := IssueChange{IssueRef: issueRef(issue)}
+		if old.Trending != issue.Trending {
+			ch.Trending = &Change{From: old.Trending, To: issue.Trending}
+		}
+		if from, to := statusForDisplay(old), statusForDisplay(issue); from != to {
+			ch.Status = &Change{From: from, To: to}
+		}
+		if old.Due != issue.Due {
+			ch.Due = &Change{From: old.Due, To: issue.Due}
+		}
+		if issue.Comment.Url != "" && issue.Comment.Url != old.Comment.Url && commentAfter(issue.Comment, base.TakenAt) {
+			c := issue.Comment
+			ch.NewComment = &c
+		}
+		if ch.Trending != nil || ch.Status != nil || ch.Due != nil || ch.NewComment != nil {
+			d.Changed = append(d.Changed, ch)
+		}
+	}
+	for key, issue := range before {
+		if !seen[key] {
+			d.Removed = append(d.Removed, issueRef(issue))
+		}
+	}
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].Key < d.Added[j].Key })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].Key < d.Removed[j].Key })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Key < d.Changed[j].Key })
+	return d
+}
+
+// commentAfter reports whether c was created after t (true when the date does not parse).
+func commentAfter(c IssueComment, t time.Time) bool {
+	created, err := ParseJiraDate(c.Created)
+	return err != nil || created.After(t)
+}
+
+// changeLines describes an IssueChange as short phrases, e.g. "🟢→🟡 on track → at risk".
+func changeLines(ch IssueChange) []string {
+	var lines []string
+	if ch.Trending != nil {
+		lines = append(lines, fmt.Sprintf("%s→%s %s → %s",
+			activeStatusMap.trendingEmoji(ch.Trending.From), activeStatusMap.trendingEmoji(ch.Trending.To),
+			orNone(ch.Trending.From), orNone(ch.Trending.To)))
+	}
+	if ch.Status != nil {
+		lines = append(lines, fmt.Sprintf("status %s → %s", orNone(ch.Status.From), orNone(ch.Status.To)))
+	}
+	if ch.Due != nil {
+		lines = append(lines, fmt.Sprintf("due %s → %s", FormatDate(ch.Due.From), FormatDate(ch.Due.To)))
+	}
+	if ch.NewComment != nil {
+		lines = append(lines, fmt.Sprintf("[new comment](%s)", ch.NewComment.Url))
+	}
+	return lines
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// RenderDiffMarkdown renders a SnapshotDiff as markdown sections.
+func RenderDiffMarkdown(d SnapshotDiff, cfg *ReportConfig) string {
+	title := ""
+	if cfg != nil {
+		title = escapeMarkdownInline(cfg.Title)
+	}
+	link := func(r IssueRef) string {
+		return fmt.Sprintf("%s [%s](%s) %s", activeStatusMap.trendingEmoji(r.Trending), escapeMarkdownInline(r.Summary), r.URL, r.Key)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n### %s — changes since %s\n\n", title, d.Since.Format("2006-01-02 15:04 MST"))
+	if len(d.Added)+len(d.Removed)+len(d.Changed) == 0 {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+	if len(d.Added) > 0 {
+		b.WriteString("#### Added\n\n")
+		for _, r := range d.Added {
+			fmt.Fprintf(&b, "* %s\n", link(r))
+		}
+		b.WriteString("\n")
+	}
+	if len(d.Removed) > 0 {
+		b.WriteString("#### Removed\n\n")
+		for _, r := range d.Removed {
+			fmt.Fprintf(&b, "* %s\n", link(r))
+		}
+		b.WriteString("\n")
+	}
+	if len(d.Changed) > 0 {
+		b.WriteString("#### Changed\n\n")
+		for _, ch := range d.Changed {
+			fmt.Fprintf(&b, "* %s: %s\n", link(ch.IssueRef), strings.Join(changeLines(ch), "; "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// RenderDiffSlack renders a SnapshotDiff as a numbered list like RenderSlackReport.
+func RenderDiffSlack(d SnapshotDiff) string {
+	var result []string
+	add := func(prefix string, r IssueRef, detail string) {
+		line := fmt.Sprintf("%d. %s %s [%s](%s)", len(result)+1, prefix, activeStatusMap.trendingEmoji(r.Trending), r.Summary, r.URL)
+		if detail != "" {
+			line += ", " + detail
+		}
+		result = append(result, line)
+	}
+	for _, r := range d.Added {
+		add("➕", r, "")
+	}
+	for _, r := range d.Removed {
+		add("➖", r, "")
+	}
+	for _, ch := range d.Changed {
+		add("✏️", ch.IssueRef, strings.Join(changeLines(ch), ", "))
+	}
+	if len(result) == 0 {
+		return fmt.Sprintf("No changes since %s.", d.Since.Format("2006-01-02"))
+	}
+	return strings.Join(result, "\n")
+}
+
+// RenderDiffJSON renders a SnapshotDiff as JSON.
+func RenderDiffJSON(d SnapshotDiff) string {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		logError("Failed to marshal diff: %v", err)
+		return "{}"
+	}
+	return string(data)
+}
+
+// RenderDiff renders a SnapshotDiff in the format cfg selects (--json, --slack, else markdown).
+func RenderDiff(d SnapshotDiff, cfg *ReportConfig) string {
+	switch {
+	case cfg != nil && cfg.JSONOutput:
+		return RenderDiffJSON(d)
+	case cfg != nil && cfg.SlackOutput:
+		return RenderDiffSlack(d)
+	default:
+		return RenderDiffMarkdown(d, cfg)
+	}
+}
+
+// loadTrendingHistory reads the newest runs snapshots of this report's query and returns each issue's
+// trending in those that include it, oldest first. The current run is the newest snapshot.
+func loadTrendingHistory(cfg *ReportConfig, issueKeys []string, issues []*IssueData, runs int) (map[string][]string, error) {
+	history := make(map[string][]string, len(issues))
+	if runs < 1 || len(issues) == 0 {
+		return history, nil
+	}
+	wanted := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		wanted[issue.Key] = true
+	}
+	times, err := listSnapshots(cfg, issueKeys)
+	if err != nil {
+		return history, err
+	}
+	dir, err := snapshotQueryDir(snapshotQuery(cfg, issueKeys))
+	if err != nil {
+		return history, err
+	}
+	for _, t := range times[max(len(times)-runs, 0):] {
+		path := filepath.Join(dir, t.Format(snapshotTimeFormat)+".json")
+		snap, err := filecache.ReadJSON[snapshotFile](path)
+		if err != nil {
+			logDebug("Skipping unreadable snapshot %s: %v", path, err)
+			continue
+		}
+		inSnapshot := make(map[string]bool)
+		for _, issue := range flattenIssues(snap.ParentIssues) {
+			if wanted[issue.Key] && !inSnapshot[issue.Key] {
+				inSnapshot[issue.Key] = true
+				history[issue.Key] = append(history[issue.Key], issue.Trending)
+			}
+		}
+	}
+	return history, nil
+}
+
+// withTrendingHistory returns a copy of cfg carrying the --history data for the issues the report
+// renders, so it is loaded once before rendering; cfg itself is not modified.
+func withTrendingHistory(cfg *ReportConfig, issueKeys []string, parentIssues []*IssueData) *ReportConfig {
+	if cfg == nil || cfg.HistoryRuns < 1 {
+		return cfg
+	}
+	history, err := loadTrendingHistory(cfg, issueKeys, flattenIssues(issuesForReport(parentIssues, cfg)), cfg.HistoryRuns)
+	if err != nil {
+		logWarning("Failed to load trending history: %v", err)
+	}
+	withHistory := *cfg
+	withHistory.trendingHistory = history
+	return &withHistory
+}
+
+// trendingSparkline renders an issue's trending history as emojis, e.g. 🟢🟢🟡🔴 (--history); issues
+// without snapshots show their current trending.
+func trendingSparkline(issue *IssueData, cfg *ReportConfig) string {
+	past := cfg.trendingHistory[issue.Key]
+	if len(past) == 0 {
+		past = []string{issue.Trending}
+	}
+	var b strings.Builder
+	for _, t := range past {
+		b.WriteString(activeStatusMap.trendingEmoji(t))
+	}
+	return b.String()
+}
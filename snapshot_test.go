@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTempSnapshotDir points the report cache (and so the snapshot store beside it) at a temp dir.
+func useTempSnapshotDir(t *testing.T) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "cache")
+	old := reportCacheDirFn
+	reportCacheDirFn = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { reportCacheDirFn = old })
+}
+
+func TestSnapshots_saveAndFind(t *testing.T) {
+	useTempSnapshotDir(t)
+	cfg := &ReportConfig{}
+	keys := []string{"B-1", "A-1"}
+	for i, at := range []time.Time{
+		time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 10, 17, 0, 0, 0, time.UTC),
+	} {
+		issues := []*IssueData{{Key: "A-1", Summary: string(rune('a' + i))}}
+		if _, err := saveSnapshot(cfg, keys, issues, at); err != nil {
+			t.Fatalf("saveSnapshot: %v", err)
+		}
+	}
+	// the same keys in another order are the same query; other queries are separate
+	if _, err := saveSnapshot(&ReportConfig{JQLQuery: "project = X"}, nil, nil, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("saveSnapshot (jql): %v", err)
+	}
+
+	now := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	for since, want := range map[string]string{
+		"":           "c",
+		"last":       "c",
+		"2025-03-10": "c",
+		"2025-03-09": "a",
+		"5":          "a", // 2025-03-07
+	} {
+		snap, err := findSnapshot(cfg, []string{"A-1", "B-1"}, since, now)
+		if err != nil {
+			t.Fatalf("findSnapshot(%q): %v", since, err)
+		}
+		if got := snap.ParentIssues[0].Summary; got != want {
+			t.Errorf("findSnapshot(%q) = snapshot %q, want %q", since, got, want)
+		}
+	}
+	if _, err := findSnapshot(cfg, keys, "2025-03-01", now); err == nil {
+		t.Error("expected an error when no snapshot is old enough")
+	}
+	if _, err := findSnapshot(cfg, keys, "last week", now); err == nil {
+		t.Error("expected an error for an unparseable --since-snapshot")
+	}
+}
+
+func TestSnapshotQuery_childDepth(t *testing.T) {
+	plain := snapshotQuery(&ReportConfig{JQLQuery: "project = X"}, nil)
+	if plain != "jql:project = X" {
+		t.Errorf("plain query = %q", plain)
+	}
+	children := snapshotQuery(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true}, nil)
+	deeper := snapshotQuery(&ReportConfig{JQLQuery: "project = X", IncludeChildren: true, ChildDepth: 2}, nil)
+	if children == plain || deeper == children {
+		t.Errorf("child levels should separate snapshots: %q %q %q", plain, children, deeper)
+	}
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	useTempSnapshotDir(t)
+	now := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	busy := &ReportConfig{JQLQuery: "busy"}
+	for i := 0; i < snapshotMaxPerQuery+5; i++ {
+		if _, err := saveSnapshot(busy, nil, nil, now.Add(-time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("saveSnapshot: %v", err)
+		}
+	}
+	stale := &ReportConfig{JQLQuery: "stale"}
+	if _, err := saveSnapshot(stale, nil, nil, now.Add(-snapshotMaxAge-time.Hour)); err != nil {
+		t.Fatalf("saveSnapshot: %v", err)
+	}
+	if err := pruneSnapshots(now); err != nil {
+		t.Fatalf("pruneSnapshots: %v", err)
+	}
+	times, err := listSnapshots(busy, nil)
+	if err != nil || len(times) != snapshotMaxPerQuery || !times[len(times)-1].Equal(now) {
+		t.Errorf("busy query: %d snapshots (newest kept: %v), err %v", len(times), len(times) > 0 && times[len(times)-1].Equal(now), err)
+	}
+	dir, _ := snapshotQueryDir(snapshotQuery(stale, nil))
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expired query dir should be removed: %v", err)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	base := snapshotFile{
+		TakenAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		ParentIssues: []*IssueData{
+			{Key: "E-1", Summary: "Epic", Trending: "on track", Status: "in progress", Due: "2025-04-01",
+				Comment:  IssueComment{Url: "u/1", Created: "2025-02-20T00:00:00.000+0000"},
+				Children: []*IssueData{{Key: "S-1", Summary: "Story", Trending: "on track", Status: "in progress"}}},
+			{Key: "E-2", Summary: "Gone", Trending: "done", Status: "closed"},
+		},
+	}
+	current := []*IssueData{
+		{Key: "E-1", Summary: "Epic", Trending: "at risk", Status: "in progress", Due: "2025-05-01",
+			Comment:  IssueComment{Url: "u/2", Created: "2025-03-05T00:00:00.000+0000"},
+			Children: []*IssueData{{Key: "S-1", Summary: "Story", Trending: "off track", Status: "blocked"}}},
+		{Key: "E-3", Summary: "New", Trending: "not started", Status: "new"},
+	}
+	d := diffSnapshots(base, current, time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC))
+
+	if len(d.Added) != 1 || d.Added[0].Key != "E-3" || len(d.Removed) != 1 || d.Removed[0].Key != "E-2" {
+		t.Fatalf("added=%+v removed=%+v", d.Added, d.Removed)
+	}
+	if len(d.Changed) != 2 {
+		t.Fatalf("changed = %+v", d.Changed)
+	}
+	epic, story := d.Changed[0], d.Changed[1]
+	if epic.Trending == nil || epic.Trending.To != "at risk" || epic.Status != nil || epic.Due == nil || epic.Due.To != "2025-05-01" {
+		t.Errorf("epic change = %+v", epic)
+	}
+	if epic.NewComment == nil || epic.NewComment.Url != "u/2" {
+		t.Errorf("epic should report the new comment: %+v", epic.NewComment)
+	}
+	if story.Status == nil || story.Status.From != "in progress" || story.Status.To != "blocked" {
+		t.Errorf("story change = %+v", story)
+	}
+
+	md := RenderDiffMarkdown(d, &ReportConfig{Title: "Weekly"})
+	for _, want := range []string{"changes since 2025-03-01", "#### Added", "#### Removed", "🟢→🟡 on track → at risk", "due 2025-04-01 → 2025-05-01", "[new comment](u/2)", "status in progress → blocked"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	slack := RenderDiffSlack(d)
+	if lines := strings.Split(slack, "\n"); len(lines) != 4 || !strings.HasPrefix(lines[0], "1. ➕") || !strings.HasPrefix(lines[3], "4. ✏️") {
+		t.Errorf("slack diff:\n%s", slack)
+	}
+	var decoded SnapshotDiff
+	if err := json.Unmarshal([]byte(RenderDiff(d, &ReportConfig{JSONOutput: true})), &decoded); err != nil || len(decoded.Changed) != 2 {
+		t.Errorf("json diff: %+v err=%v", decoded, err)
+	}
+
+	unchanged := diffSnapshots(base, base.ParentIssues, base.TakenAt)
+	if out := RenderDiffMarkdown(unchanged, &ReportConfig{}); !strings.Contains(out, "No changes.") {
+		t.Errorf("identical snapshots should report no changes:\n%s", out)
+	}
+}
+
+func TestTrendingHistory_sparklineInMarkdownAndSimple(t *testing.T) {
+	useTempSnapshotDir(t)
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, trending := range []string{"on track", "on track", "at risk", "off track"} {
+		issues := []*IssueData{{Key: "E-1", Trending: trending}}
+		if i == 3 {
+			issues = append(issues, &IssueData{Key: "E-2", Trending: "done"})
+		}
+		if _, err := saveSnapshot(&ReportConfig{JQLQuery: "a"}, nil, issues, start.AddDate(0, 0, 7*i)); err != nil {
+			t.Fatalf("saveSnapshot: %v", err)
+		}
+	}
+	// another query's runs are not this report's history
+	other := []*IssueData{{Key: "E-1", Trending: "done"}}
+	if _, err := saveSnapshot(&ReportConfig{JQLQuery: "b"}, nil, other, start.AddDate(0, 0, 30)); err != nil {
+		t.Fatalf("saveSnapshot: %v", err)
+	}
+
+	current := []*IssueData{
+		{Key: "E-1", Summary: "Epic", Trending: "off track", TrendingEmoji: "🔴", URL: "https://jira/browse/E-1"},
+		{Key: "E-2", Summary: "Done", Trending: "done", TrendingEmoji: "✅", URL: "https://jira/browse/E-2"},
+		{Key: "E-3", Summary: "No history", Trending: "at risk", TrendingEmoji: "🟡", URL: "https://jira/browse/E-3"},
+	}
+	history, err := loadTrendingHistory(&ReportConfig{JQLQuery: "a"}, nil, current, 3)
+	if err != nil {
+		t.Fatalf("loadTrendingHistory: %v", err)
+	}
+	if got := strings.Join(history["E-1"], ","); got != "on track,at risk,off track" {
+		t.Errorf("E-1 history = %s", got)
+	}
+
+	base := &ReportConfig{Title: "T", JQLQuery: "a", HistoryRuns: 3}
+	cfg := withTrendingHistory(base, nil, current)
+	if base.trendingHistory != nil || strings.Join(cfg.trendingHistory["E-1"], ",") != "on track,at risk,off track" {
+		t.Errorf("withTrendingHistory should fill a copy: base=%v copy=%v", base.trendingHistory, cfg.trendingHistory)
+	}
+	md := RenderMarkdownReport(current, cfg)
+	if !strings.Contains(md, "| trending | history | type |") || !strings.Contains(md, "| 🔴 off track | 🟢🟡🔴 |") {
+		t.Errorf("markdown should show the sparkline column:\n%s", md)
+	}
+	simple := RenderSimpleReport(current, cfg)
+	if !strings.Contains(simple, "🟢🟡🔴") || !strings.Contains(simple, "🟡  ") {
+		t.Errorf("simple report should show sparklines:\n%s", simple)
+	}
+	if md := RenderMarkdownReport(current, &ReportConfig{Title: "T"}); strings.Contains(md, "| history |") {
+		t.Errorf("history column should be off by default:\n%s", md)
+	}
+}
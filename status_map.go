@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// statusMapFileName is the optional JSON file that customizes status handling (see statusMapFile).
+const statusMapFileName = ".snippets/statuses.json"
+
+// defaultStatusTrending is the trending each built-in status starts from, before due-date and child rules.
+var defaultStatusTrending = map[string]string{
+	"closed":         "done",
+	"resolved":       "done",
+	"in progress":    "on track",
+	"blocked":        "off track",
+	"ready for work": "not started",
+	"vetting":        "not started",
+	"new":            "not started",
+	"unknown":        "unknown",
+}
+
+// StatusMapping is one entry of the status map file. Empty fields inherit from the canonical status.
+type StatusMapping struct {
+	Status   string `json:"status,omitempty"`   // canonical status; defaults to the entry's own name
+	Priority *int   `json:"priority,omitempty"` // sort priority, lowest first
+	Emoji    string `json:"emoji,omitempty"`
+	Trending string `json:"trending,omitempty"` // default trending: done, on track, at risk, off track, not started
+}
+
+// statusMapFile is the on-disk format of ~/.snippets/statuses.json, e.g.
+//
+//	{
+//	  "statuses": {
+//	    "In Review": {"status": "in progress", "emoji": "👀"},
+//	    "Won't Do":  {"status": "closed", "priority": 0, "emoji": "🚫"},
+//	    "Parked":    {"priority": 5, "emoji": "🅿️", "trending": "not started"}
+//	  },
+//	  "trending_emojis": {"on track": "✅"}
+//	}
+type statusMapFile struct {
+	Statuses       map[string]StatusMapping `json:"statuses"`
+	TrendingEmojis map[string]string        `json:"trending_emojis"`
+}
+
+// statusInfo is a fully resolved status map entry.
+type statusInfo struct {
+	Status   string
+	Priority int
+	Emoji    string
+	Trending string
+}
+
+// statusMap resolves Jira status names (case-insensitive) to canonical status, sort priority, emoji,
+// and default trending. The built-in defaults come from statusOrder, statusEmojis, and defaultStatusTrending.
+type statusMap struct {
+	statuses       map[string]statusInfo // lowercased status name -> info
+	trendingEmojis map[string]string
+}
+
+// activeStatusMap is used by extraction, trending, and rendering; main replaces it when a status map file exists.
+var activeStatusMap = defaultStatusMap()
+
+func defaultStatusMap() *statusMap {
+	m := &statusMap{
+		statuses:       make(map[string]statusInfo, len(statusOrder)),
+		trendingEmojis: make(map[string]string, len(trendingEmojis)),
+	}
+	for i, s := range statusOrder {
+		m.statuses[s] = statusInfo{Status: s, Priority: i, Emoji: statusEmojis[s], Trending: defaultStatusTrending[s]}
+	}
+	for k, v := range trendingEmojis {
+		m.trendingEmojis[k] = v
+	}
+	return m
+}
+
+// lookup returns the info for a status name, if mapped.
+func (m *statusMap) lookup(name string) (statusInfo, bool) {
+	info, ok := m.statuses[strings.ToLower(strings.TrimSpace(name))]
+	return info, ok
+}
+
+// forIssue resolves an issue's Jira status name first, then its canonical Status.
+func (m *statusMap) forIssue(issue *IssueData) statusInfo {
+	if info, ok := m.lookup(issue.StatusName); ok {
+		return info
+	}
+	if info, ok := m.lookup(issue.Status); ok {
+		return info
+	}
+	return statusInfo{Status: issue.Status, Priority: 999}
+}
+
+// trendingEmoji returns the emoji for a trending value, or ❓ when unmapped.
+func (m *statusMap) trendingEmoji(trending string) string {
+	if e, ok := m.trendingEmojis[trending]; ok {
+		return e
+	}
+	return "❓"
+}
+
+// apply merges file entries over m. Entries that define a canonical status (no "status", or one equal to
+// their own name) are applied first so that aliases pointing at them inherit the customized values.
+// Aliases must point at a canonical status, not at another alias.
+func (m *statusMap) apply(f statusMapFile) error {
+	canonicalOf := func(name string, sm StatusMapping) string {
+		if c := strings.ToLower(strings.TrimSpace(sm.Status)); c != "" {
+			return c
+		}
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+	aliasNames := make(map[string]bool)
+	for name, sm := range f.Statuses {
+		if key := strings.ToLower(strings.TrimSpace(name)); canonicalOf(name, sm) != key {
+			aliasNames[key] = true
+		}
+	}
+	for _, aliases := range []bool{false, true} {
+		for name, sm := range f.Statuses {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if key == "" {
+				return errors.New("status map: empty status name")
+			}
+			canonical := canonicalOf(name, sm)
+			if (canonical != key) != aliases {
+				continue
+			}
+			if aliases && aliasNames[canonical] {
+				return fmt.Errorf("status map: %q maps to %q, which is itself mapped; point it at a canonical status", name, sm.Status)
+			}
+			base, ok := m.statuses[canonical]
+			if !ok {
+				if aliases {
+					return fmt.Errorf("status map: %q maps to undefined status %q", name, sm.Status)
+				}
+				base = statusInfo{Priority: 999}
+			}
+			base.Status = canonical
+			if sm.Priority != nil {
+				base.Priority = *sm.Priority
+			}
+			if sm.Emoji != "" {
+				base.Emoji = sm.Emoji
+			}
+			if t := strings.ToLower(strings.TrimSpace(sm.Trending)); t != "" {
+				base.Trending = t
+			}
+			m.statuses[key] = base
+		}
+	}
+	for k, v := range f.TrendingEmojis {
+		m.trendingEmojis[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return nil
+}
+
+// readConfigFile reads an optional JSON config file. path "" means ~/<defaultName>, which may be absent
+// (nil data, no error); an explicit path must exist. usedPath is the file actually read.
+func readConfigFile(path, defaultName string) (data []byte, usedPath string, err error) {
+	explicit := path != ""
+	if !explicit {
+		home, homeErr := os.UserHomeDir()
+		if homeErr != nil {
+			return nil, "", nil
+		}
+		path = filepath.Join(home, defaultName)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return data, path, nil
+}
+
+// configDigest returns a short content hash of a config file for cache keys.
+func configDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadStatusMap returns the defaults merged with the status map file and a digest of the file contents
+// (for cache keys; "" when no file was used). path "" means ~/.snippets/statuses.json, which may be absent;
+// an explicit path must exist.
+func loadStatusMap(path string) (*statusMap, string, error) {
+	m := defaultStatusMap()
+	data, path, err := readConfigFile(path, statusMapFileName)
+	if err != nil {
+		return nil, "", fmt.Errorf("read status map: %w", err)
+	}
+	if data == nil {
+		return m, "", nil
+	}
+	var f statusMapFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, "", fmt.Errorf("parse status map %s: %w", path, err)
+	}
+	if err := m.apply(f); err != nil {
+		return nil, "", err
+	}
+	logDebug("Loaded %d status mappings from %s", len(f.Statuses), path)
+	return m, configDigest(data), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useStatusMap installs m as activeStatusMap for the duration of a test.
+func useStatusMap(t *testing.T, m *statusMap) {
+	t.Helper()
+	old := activeStatusMap
+	activeStatusMap = m
+	t.Cleanup(func() { activeStatusMap = old })
+}
+
+func writeStatusMap(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "statuses.json")
+	if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatalf("write status map: %v", err)
+	}
+	return path
+}
+
+func TestLoadStatusMap_defaultsWhenAbsent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, digest, err := loadStatusMap("")
+	if err != nil || digest != "" {
+		t.Fatalf("missing default file: digest=%q err=%v", digest, err)
+	}
+	if info, ok := m.lookup("Blocked"); !ok || info.Priority != 3 || info.Trending != "off track" || info.Emoji != "🛑" {
+		t.Errorf("blocked = %+v", info)
+	}
+	if _, _, err := loadStatusMap(filepath.Join(t.TempDir(), "nope.json")); err == nil {
+		t.Error("explicit missing path should be an error")
+	}
+}
+
+func TestLoadStatusMap_aliasesInheritCanonical(t *testing.T) {
+	path := writeStatusMap(t, `{
+  "statuses": {
+    "In Review": {"status": "in progress", "emoji": "👀"},
+    "in progress": {"priority": 1, "emoji": "🏃"},
+    "Won't Do": {"status": "closed", "trending": "done"},
+    "Parked": {"priority": 5, "emoji": "🅿️", "trending": "not started"}
+  },
+  "trending_emojis": {"On Track": "✅"}
+}`)
+	m, digest, err := loadStatusMap(path)
+	if err != nil {
+		t.Fatalf("loadStatusMap: %v", err)
+	}
+	if digest == "" {
+		t.Error("expected a digest for a loaded file")
+	}
+	review, _ := m.lookup("in review")
+	if review.Status != "in progress" || review.Priority != 1 || review.Emoji != "👀" || review.Trending != "on track" {
+		t.Errorf("In Review = %+v, want in progress / 1 / 👀 / on track", review)
+	}
+	if ip, _ := m.lookup("In Progress"); ip.Emoji != "🏃" || ip.Priority != 1 {
+		t.Errorf("in progress = %+v", ip)
+	}
+	if parked, _ := m.lookup("PARKED"); parked.Status != "parked" || parked.Priority != 5 {
+		t.Errorf("Parked = %+v", parked)
+	}
+	if got := m.trendingEmoji("on track"); got != "✅" {
+		t.Errorf("trending emoji = %q", got)
+	}
+}
+
+func TestLoadStatusMap_rejectsBadAliases(t *testing.T) {
+	for name, body := range map[string]string{
+		"undefined target": `{"statuses": {"QA": {"status": "testing"}}}`,
+		"alias chain":      `{"statuses": {"QA": {"status": "In Review"}, "In Review": {"status": "in progress"}}}`,
+		"bad json":         `{"statuses": [}`,
+	} {
+		if _, _, err := loadStatusMap(writeStatusMap(t, body)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestStatusMap_drivesExtractionTrendingAndSorting(t *testing.T) {
+	m, _, err := loadStatusMap(writeStatusMap(t, `{"statuses": {
+  "In Review": {"status": "in progress", "priority": -1, "emoji": "👀"},
+  "Parked": {"trending": "off track", "emoji": "🅿️"}
+}}`))
+	if err != nil {
+		t.Fatalf("loadStatusMap: %v", err)
+	}
+	useStatusMap(t, m)
+
+	extract := func(key, status string) *IssueData {
+		return testJiraClientForExtract(nil, nil).extractIssueData(map[string]any{
+			"key":    key,
+			"fields": map[string]any{"summary": key, "status": map[string]any{"name": status}},
+		})
+	}
+	review := extract("R-1", "In Review")
+	if review.Status != "in progress" || review.StatusEmoji != "👀" {
+		t.Errorf("In Review: Status=%q emoji=%q", review.Status, review.StatusEmoji)
+	}
+	parked := extract("P-1", "Parked")
+	computeTrending(parked, false)
+	if parked.Status != "parked" || parked.Trending != "off track" {
+		t.Errorf("Parked: Status=%q Trending=%q", parked.Status, parked.Trending)
+	}
+
+	closed := extract("C-1", "Closed")
+	sorted := filterAndSortIssues([]*IssueData{closed, review}, &ReportConfig{})
+	if sorted[0].Key != "R-1" {
+		t.Errorf("In Review (priority -1) should sort before Closed: %s, %s", sorted[0].Key, sorted[1].Key)
+	}
+	if GetStatusPriority("parked") != 999 || GetStatusPriority("in review") != -1 {
+		t.Errorf("GetStatusPriority: parked=%d in review=%d", GetStatusPriority("parked"), GetStatusPriority("in review"))
+	}
+	if !strings.Contains(RenderSimpleReport([]*IssueData{review}, &ReportConfig{}), "👀") {
+		t.Error("renderers should show the mapped status emoji")
+	}
+}
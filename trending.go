@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// trendingRulesFileName is the optional JSON file that replaces defaultTrendingRules (see trendingRulesFile).
+const trendingRulesFileName = ".snippets/trending.json"
+
+// IntRange bounds an integer condition; a nil end is open.
+type IntRange struct {
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+}
+
+func (r *IntRange) contains(n int) bool {
+	return (r.Min == nil || n >= *r.Min) && (r.Max == nil || n <= *r.Max)
+}
+
+// ChildCondition matches on the computed trending of an issue's children. It never matches without
+// --children or when the issue has no children. With neither minimum set, one matching child suffices.
+type ChildCondition struct {
+	Trending   []string `json:"trending"`
+	MinCount   int      `json:"min_count,omitempty"`
+	MinPercent float64  `json:"min_percent,omitempty"` // 0-100 share of children
+}
+
+// TrendingConditions are ANDed; unset conditions match everything. Lists match any entry, case-insensitively.
+type TrendingConditions struct {
+	Status           []string        `json:"status,omitempty"`           // canonical status or Jira status name
+	Type             []string        `json:"type,omitempty"`             // issue type, e.g. epic
+	Priority         []string        `json:"priority,omitempty"`         // Jira priority name
+	DefaultTrending  []string        `json:"default_trending,omitempty"` // the status map's trending for the status
+	DueInDays        *IntRange       `json:"due_in_days,omitempty"`      // negative is overdue; issues without a due date never match
+	DaysSinceComment *IntRange       `json:"days_since_comment,omitempty"`
+	DueSlips         *IntRange       `json:"due_slips,omitempty"` // times the due date moved later; needs --changelog
+	Children         *ChildCondition `json:"children,omitempty"`
+}
+
+// TrendingRule sets an issue's trending when all of its conditions hold. Rules are tried in order and the
+// first match wins. An empty Trending keeps the status map's default trending. Comment is a text/template
+// rendered with a trendingContext, e.g. "child {{.Child.Key}} is '{{.Child.Trending}}'".
+type TrendingRule struct {
+	Name     string             `json:"name,omitempty"`
+	When     TrendingConditions `json:"when"`
+	Trending string             `json:"trending,omitempty"`
+	Comment  string             `json:"comment,omitempty"`
+
+	comment *template.Template
+}
+
+// trendingRulesFile is the on-disk format of ~/.snippets/trending.json, e.g.
+//
+//	{
+//	  "rules": [
+//	    {"name": "done", "when": {"default_trending": ["done"]}, "trending": "done", "comment": "🎉"},
+//	    {"name": "quiet", "when": {"days_since_comment": {"min": 14}, "type": ["epic"]},
+//	     "trending": "at risk", "comment": "no update for {{.DaysSinceComment}} days"},
+//	    {"name": "slipping", "when": {"due_slips": {"min": 3}},
+//	     "trending": "at risk", "comment": "due date moved {{.Issue.DueSlips}} times (was {{.Issue.OriginalDue}})"},
+//	    {"name": "half the children late", "when": {"children": {"trending": ["off track"], "min_percent": 50}},
+//	     "trending": "off track", "comment": "{{.ChildMatches}} of {{.ChildCount}} children off track"},
+//	    {"name": "default", "when": {}}
+//	  ]
+//	}
+//
+// The file replaces defaultTrendingRules entirely.
+type trendingRulesFile struct {
+	Rules []*TrendingRule `json:"rules"`
+}
+
+// trendingContext is what rule conditions are evaluated against and comment templates are rendered with.
+type trendingContext struct {
+	Issue            *IssueData
+	DefaultTrending  string
+	DueInDays        int // valid when HasDue
+	HasDue           bool
+	DaysSinceComment int // valid when HasComment
+	HasComment       bool
+	Child            *IssueData // first child matching the rule's children condition
+	ChildMatches     int
+	ChildCount       int
+	ChildPercent     float64
+
+	children []*IssueData // nil unless children were loaded
+}
+
+// activeTrendingRules drive computeTrending; main replaces them when a trending rules file exists.
+var activeTrendingRules = defaultTrendingRules()
+
+func intPtr(n int) *int { return &n }
+
+// defaultTrendingRules encode the built-in policy: unknown statuses stay unknown, done is done, past due is
+// off track, not started but due within 30 days is at risk, an off track or at risk status stands, otherwise
+// an off track child makes the parent off track, then an at risk child makes it at risk, and all children
+// done makes it done. Everything else keeps the status map's default trending.
+//
+// Child roll-up goes by severity: any off track (or blocked) child beats any at risk child. The hardcoded
+// policy these rules replaced let the last flagged child in list order win, so a parent whose children were
+// [off track, at risk] was reported at risk.
+func defaultTrendingRules() []*TrendingRule {
+	rules := []*TrendingRule{
+		{Name: "unknown status", When: TrendingConditions{Status: []string{"unknown"}}, Trending: "unknown"},
+		{Name: "done", When: TrendingConditions{DefaultTrending: []string{"done"}}, Trending: "done", Comment: "🎉"},
+		{Name: "past due", When: TrendingConditions{DueInDays: &IntRange{Max: intPtr(-1)}}, Trending: "off track"},
+		{
+			Name:     "not started but due soon",
+			When:     TrendingConditions{DefaultTrending: []string{"not started"}, DueInDays: &IntRange{Min: intPtr(1), Max: intPtr(30)}},
+			Trending: "at risk",
+			Comment:  "due within 30 days but not started.",
+		},
+		{Name: "status already off track or at risk", When: TrendingConditions{DefaultTrending: []string{"off track", "at risk"}}},
+		{
+			Name:     "child off track",
+			When:     TrendingConditions{Children: &ChildCondition{Trending: []string{"off track", "blocked"}}},
+			Trending: "off track",
+			Comment:  "child {{.Child.Key}} is '{{.Child.Trending}}'",
+		},
+		{
+			Name:     "child at risk",
+			When:     TrendingConditions{Children: &ChildCondition{Trending: []string{"at risk"}}},
+			Trending: "at risk",
+			Comment:  "child {{.Child.Key}} is '{{.Child.Trending}}'",
+		},
+		{
+			Name:     "all children done",
+			When:     TrendingConditions{Children: &ChildCondition{Trending: []string{"done"}, MinPercent: 100}},
+			Trending: "done",
+			Comment:  "All children are done. What's left?",
+		},
+	}
+	if err := prepareTrendingRules(rules); err != nil {
+		panic(err) // the built-in rules are static
+	}
+	return rules
+}
+
+// prepareTrendingRules normalizes rule values and parses comment templates.
+func prepareTrendingRules(rules []*TrendingRule) error {
+	lower := func(list []string) []string {
+		out := make([]string, 0, len(list))
+		for _, s := range list {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	for i, r := range rules {
+		if r == nil {
+			return fmt.Errorf("trending rule %d is empty", i+1)
+		}
+		label := r.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		r.Trending = strings.ToLower(strings.TrimSpace(r.Trending))
+		w := &r.When
+		w.Status, w.Type, w.Priority, w.DefaultTrending = lower(w.Status), lower(w.Type), lower(w.Priority), lower(w.DefaultTrending)
+		if c := w.Children; c != nil {
+			c.Trending = lower(c.Trending)
+			if len(c.Trending) == 0 {
+				return fmt.Errorf("trending rule %s: children condition needs at least one trending value", label)
+			}
+			if c.MinPercent < 0 || c.MinPercent > 100 {
+				return fmt.Errorf("trending rule %s: min_percent must be between 0 and 100", label)
+			}
+		}
+		r.comment = nil
+		if r.Comment != "" {
+			t, err := template.New(label).Option("missingkey=zero").Parse(r.Comment)
+			if err != nil {
+				return fmt.Errorf("trending rule %s: comment: %w", label, err)
+			}
+			r.comment = t
+		}
+	}
+	return nil
+}
+
+// matches reports whether every condition of the rule holds for tc, filling tc's child fields for templates.
+func (r *TrendingRule) matches(tc *trendingContext) bool {
+	w := &r.When
+	issue := tc.Issue
+	if len(w.Status) > 0 && !slices.Contains(w.Status, strings.ToLower(issue.Status)) &&
+		!slices.Contains(w.Status, strings.ToLower(strings.TrimSpace(issue.StatusName))) {
+		return false
+	}
+	if len(w.Type) > 0 && !slices.Contains(w.Type, strings.ToLower(issue.Type)) {
+		return false
+	}
+	if len(w.Priority) > 0 && !slices.Contains(w.Priority, strings.ToLower(strings.TrimSpace(issue.Priority))) {
+		return false
+	}
+	if len(w.DefaultTrending) > 0 && !slices.Contains(w.DefaultTrending, tc.DefaultTrending) {
+		return false
+	}
+	if w.DueInDays != nil && (!tc.HasDue || !w.DueInDays.contains(tc.DueInDays)) {
+		return false
+	}
+	// Never-commented issues count as infinitely quiet: they satisfy a minimum but not a maximum.
+	if d := w.DaysSinceComment; d != nil {
+		if tc.HasComment && !d.contains(tc.DaysSinceComment) || !tc.HasComment && d.Max != nil {
+			return false
+		}
+	}
+	if w.DueSlips != nil && !w.DueSlips.contains(dueSlipCount(issue)) {
+		return false
+	}
+	tc.Child, tc.ChildMatches, tc.ChildPercent = nil, 0, 0
+	if c := w.Children; c != nil {
+		if len(tc.children) == 0 {
+			return false
+		}
+		for _, child := range tc.children {
+			if slices.Contains(c.Trending, child.Trending) {
+				if tc.Child == nil {
+					tc.Child = child
+				}
+				tc.ChildMatches++
+			}
+		}
+		tc.ChildPercent = 100 * float64(tc.ChildMatches) / float64(tc.ChildCount)
+		minCount := c.MinCount
+		if minCount == 0 && c.MinPercent == 0 {
+			minCount = 1
+		}
+		if tc.ChildMatches < minCount || tc.ChildPercent < c.MinPercent {
+			return false
+		}
+	}
+	return true
+}
+
+// renderComment executes the rule's comment template; on error the raw template text is used.
+func (r *TrendingRule) renderComment(tc *trendingContext) string {
+	var b bytes.Buffer
+	if err := r.comment.Execute(&b, tc); err != nil {
+		logWarning("Trending rule %q: comment template: %v", r.Name, err)
+		return r.Comment
+	}
+	return b.String()
+}
+
+// newTrendingContext gathers the facts rules are evaluated against.
+func newTrendingContext(issue *IssueData, includeChildren bool) *trendingContext {
+	tc := &trendingContext{Issue: issue, DefaultTrending: activeStatusMap.forIssue(issue).Trending}
+	tc.DueInDays, tc.HasDue = DaysFromNow(issue.Due)
+	if created := issue.Comment.Created; created != "" && created != "N/A" {
+		if days, ok := DaysFromNow(created); ok {
+			tc.DaysSinceComment, tc.HasComment = -days, true
+		}
+	}
+	if includeChildren {
+		tc.children = issue.Children
+		tc.ChildCount = len(issue.Children)
+	}
+	return tc
+}
+
+// computeTrending computes the trending status for an issue (and, with includeChildren, its children
+// first) by applying activeTrendingRules; the first matching rule sets trending and comment.
+func computeTrending(issue *IssueData, includeChildren bool) {
+	if issue.Trending != "" {
+		return // already computed
+	}
+	if includeChildren {
+		for _, child := range issue.Children {
+			computeTrending(child, includeChildren)
+		}
+	}
+
+	tc := newTrendingContext(issue, includeChildren)
+	trending := tc.DefaultTrending // "" is inconclusive
+	for _, rule := range activeTrendingRules {
+		if !rule.matches(tc) {
+			continue
+		}
+		logDebug("%s: trending rule %q matched", issue.Key, rule.Name)
+		if rule.Trending != "" {
+			trending = rule.Trending
+		}
+		if rule.comment != nil {
+			issue.TrendingComment = rule.renderComment(tc)
+		}
+		break
+	}
+
+	// save values
+	issue.Trending = trending
+	issue.TrendingEmoji = activeStatusMap.trendingEmoji(trending)
+}
+
+// usesDueSlips reports whether any rule needs changelogs (a due_slips condition).
+func usesDueSlips(rules []*TrendingRule) bool {
+	for _, r := range rules {
+		if r.When.DueSlips != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTrendingRules returns the rules from the trending rules file, or defaultTrendingRules when there is
+// none, plus a digest of the file contents for cache keys ("" when no file was used).
+// path "" means ~/.snippets/trending.json, which may be absent; an explicit path must exist.
+func loadTrendingRules(path string) ([]*TrendingRule, string, error) {
+	data, path, err := readConfigFile(path, trendingRulesFileName)
+	if err != nil {
+		return nil, "", fmt.Errorf("read trending rules: %w", err)
+	}
+	if data == nil {
+		return defaultTrendingRules(), "", nil
+	}
+	var f trendingRulesFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields() // catch misspelled conditions instead of silently matching everything
+	if err := dec.Decode(&f); err != nil {
+		return nil, "", fmt.Errorf("parse trending rules %s: %w", path, err)
+	}
+	if len(f.Rules) == 0 {
+		return nil, "", errors.New("trending rules file has no rules")
+	}
+	if err := prepareTrendingRules(f.Rules); err != nil {
+		return nil, "", err
+	}
+	logDebug("Loaded %d trending rules from %s", len(f.Rules), path)
+	return f.Rules, configDigest(data), nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTrendingRules installs rules as activeTrendingRules for the duration of a test.
+func useTrendingRules(t *testing.T, rules []*TrendingRule) {
+	t.Helper()
+	old := activeTrendingRules
+	activeTrendingRules = rules
+	t.Cleanup(func() { activeTrendingRules = old })
+}
+
+func daysFromToday(n int) string {
+	return time.Now().UTC().AddDate(0, 0, n).Format("2006-01-02")
+}
+
+func TestComputeTrending_includeChildren(t *testing.T) {
+	parentWithResolvedChildren := func() *IssueData {
+		return &IssueData{
+			Key:    "P-1",
+			Status: "in progress",
+			Children: []*IssueData{
+				{Key: "C-1", Status: "resolved"},
+				{Key: "C-2", Status: "resolved"},
+			},
+		}
+	}
+
+	t.Run("false leaves children uncomputed and parent on track", func(t *testing.T) {
+		parent := parentWithResolvedChildren()
+		computeTrending(parent, false)
+		if parent.Trending != "on track" {
+			t.Errorf("parent Trending = %q, want on track", parent.Trending)
+		}
+		for _, child := range parent.Children {
+			if child.Trending != "" {
+				t.Errorf("child %s Trending = %q, want empty when includeChildren is false", child.Key, child.Trending)
+			}
+		}
+	})
+
+	t.Run("true computes children and rolls up when all done", func(t *testing.T) {
+		parent := parentWithResolvedChildren()
+		computeTrending(parent, true)
+		if parent.Trending != "done" {
+			t.Errorf("parent Trending = %q, want done", parent.Trending)
+		}
+		if parent.TrendingComment != "All children are done. What's left?" {
+			t.Errorf("parent TrendingComment = %q, want all-children-done message", parent.TrendingComment)
+		}
+		for _, child := range parent.Children {
+			if child.Trending != "done" || child.TrendingEmoji != "🟣" {
+				t.Errorf("child %s trending = %q emoji = %q, want done/🟣", child.Key, child.Trending, child.TrendingEmoji)
+			}
+		}
+	})
+
+	t.Run("true propagates child off track to parent", func(t *testing.T) {
+		parent := &IssueData{
+			Key:    "P-1",
+			Status: "in progress",
+			Children: []*IssueData{
+				{Key: "C-1", Status: "blocked"},
+			},
+		}
+		computeTrending(parent, true)
+		if parent.Trending != "off track" {
+			t.Errorf("parent Trending = %q, want off track", parent.Trending)
+		}
+		if parent.Children[0].Trending != "off track" {
+			t.Errorf("child Trending = %q, want off track", parent.Children[0].Trending)
+		}
+	})
+}
+
+func TestComputeTrending_defaultRules(t *testing.T) {
+	tests := []struct {
+		name         string
+		issue        *IssueData
+		wantTrending string
+		wantComment  string
+	}{
+		{"unknown status", &IssueData{Key: "A-1", Status: "unknown"}, "unknown", ""},
+		{"resolved", &IssueData{Key: "A-1", Status: "resolved", Due: daysFromToday(-10)}, "done", "🎉"},
+		{"in progress past due", &IssueData{Key: "A-1", Status: "in progress", Due: daysFromToday(-1)}, "off track", ""},
+		{"new past due", &IssueData{Key: "A-1", Status: "new", Due: daysFromToday(-1)}, "off track", ""},
+		{"new due soon", &IssueData{Key: "A-1", Status: "new", Due: daysFromToday(30)}, "at risk", "due within 30 days but not started."},
+		{"new due later", &IssueData{Key: "A-1", Status: "new", Due: daysFromToday(31)}, "not started", ""},
+		{"blocked ignores done children", &IssueData{Key: "A-1", Status: "blocked", Children: []*IssueData{{Key: "C-1", Status: "resolved"}}}, "off track", ""},
+		{"off track child beats at risk child", &IssueData{Key: "A-1", Status: "in progress", Children: []*IssueData{
+			{Key: "C-1", Status: "new", Due: daysFromToday(5)},
+			{Key: "C-2", Status: "blocked"},
+		}}, "off track", "child C-2 is 'off track'"},
+		// Precedence, not list order, decides: before configurable rules the last flagged child won.
+		{"off track child beats later at risk child", &IssueData{Key: "A-1", Status: "in progress", Children: []*IssueData{
+			{Key: "C-1", Status: "blocked"},
+			{Key: "C-2", Status: "new", Due: daysFromToday(5)},
+		}}, "off track", "child C-1 is 'off track'"},
+		{"at risk child", &IssueData{Key: "A-1", Status: "new", Children: []*IssueData{
+			{Key: "C-1", Status: "in progress"},
+			{Key: "C-2", Status: "new", Due: daysFromToday(5)},
+		}}, "at risk", "child C-2 is 'at risk'"},
+		{"children still going", &IssueData{Key: "A-1", Status: "in progress", Children: []*IssueData{
+			{Key: "C-1", Status: "resolved"},
+			{Key: "C-2", Status: "in progress"},
+		}}, "on track", ""},
+	}
+	for _, tt := range tests {
+		computeTrending(tt.issue, true)
+		if tt.issue.Trending != tt.wantTrending || tt.issue.TrendingComment != tt.wantComment {
+			t.Errorf("%s: got %q / %q, want %q / %q", tt.name, tt.issue.Trending, tt.issue.TrendingComment, tt.wantTrending, tt.wantComment)
+		}
+	}
+}
+
+func TestComputeTrending_childrenComputedEvenWhenParentDone(t *testing.T) {
+	parent := &IssueData{Key: "P-1", Status: "closed", Children: []*IssueData{{Key: "C-1", Status: "in progress"}}}
+	computeTrending(parent, true)
+	if parent.Children[0].Trending != "on track" {
+		t.Errorf("child Trending = %q, want on track for --render-children", parent.Children[0].Trending)
+	}
+}
+
+func writeTrendingRules(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "trending.json")
+	if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatalf("write trending rules: %v", err)
+	}
+	return path
+}
+
+func TestLoadTrendingRules_customRules(t *testing.T) {
+	rules, digest, err := loadTrendingRules(writeTrendingRules(t, `{"rules": [
+  {"name": "quiet epics", "when": {"type": ["Epic"], "days_since_comment": {"min": 14}},
+   "trending": "at risk", "comment": "{{.Issue.Key}}: no update for {{if .HasComment}}{{.DaysSinceComment}} days{{else}}ever{{end}}"},
+  {"name": "urgent", "when": {"priority": ["Highest"], "status": ["In Review"]}, "trending": "off track"},
+  {"name": "half late", "when": {"children": {"trending": ["off track"], "min_percent": 50}},
+   "trending": "off track", "comment": "{{.ChildMatches}} of {{.ChildCount}} children off track"},
+  {"name": "default", "when": {}}
+]}`))
+	if err != nil {
+		t.Fatalf("loadTrendingRules: %v", err)
+	}
+	if digest == "" || len(rules) != 4 {
+		t.Fatalf("digest=%q rules=%d", digest, len(rules))
+	}
+	useTrendingRules(t, rules)
+
+	stale := &IssueData{Key: "E-1", Type: "epic", Status: "in progress", Comment: IssueComment{Created: daysFromToday(-20) + "T10:00:00.000+0000"}}
+	computeTrending(stale, false)
+	if stale.Trending != "at risk" || stale.TrendingComment != "E-1: no update for 20 days" {
+		t.Errorf("stale epic: %q / %q", stale.Trending, stale.TrendingComment)
+	}
+	silent := &IssueData{Key: "E-2", Type: "epic", Status: "in progress", Comment: IssueComment{Created: "N/A"}}
+	computeTrending(silent, false)
+	if silent.TrendingComment != "E-2: no update for ever" {
+		t.Errorf("never-commented epic: %q / %q", silent.Trending, silent.TrendingComment)
+	}
+	fresh := &IssueData{Key: "E-3", Type: "epic", Status: "in progress", Comment: IssueComment{Created: daysFromToday(-2) + "T10:00:00.000+0000"}}
+	computeTrending(fresh, false)
+	if fresh.Trending != "on track" || fresh.TrendingComment != "" {
+		t.Errorf("fresh epic should fall through to the default trending: %q / %q", fresh.Trending, fresh.TrendingComment)
+	}
+
+	urgent := &IssueData{Key: "U-1", Status: "in progress", StatusName: "In Review", Priority: "Highest"}
+	computeTrending(urgent, false)
+	if urgent.Trending != "off track" {
+		t.Errorf("priority + status name rule: %q", urgent.Trending)
+	}
+
+	parent := &IssueData{Key: "P-1", Status: "in progress", Children: []*IssueData{
+		{Key: "C-1", Status: "in progress", Trending: "off track"},
+		{Key: "C-2", Status: "in progress"},
+		{Key: "C-3", Status: "in progress", Trending: "off track"},
+	}}
+	computeTrending(parent, true)
+	if parent.Trending != "off track" || parent.TrendingComment != "2 of 3 children off track" {
+		t.Errorf("child percent rule: %q / %q", parent.Trending, parent.TrendingComment)
+	}
+	parent = &IssueData{Key: "P-2", Status: "in progress", Children: []*IssueData{
+		{Key: "C-1", Status: "in progress", Trending: "off track"},
+		{Key: "C-2", Status: "in progress"},
+		{Key: "C-3", Status: "in progress"},
+	}}
+	computeTrending(parent, true)
+	if parent.Trending != "on track" {
+		t.Errorf("1 of 3 children is below min_percent 50: %q", parent.Trending)
+	}
+}
+
+func TestLoadTrendingRules_errors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if rules, digest, err := loadTrendingRules(""); err != nil || digest != "" || len(rules) != len(defaultTrendingRules()) {
+		t.Errorf("missing default file should give built-in rules: %d rules, digest=%q, err=%v", len(rules), digest, err)
+	}
+	for name, body := range map[string]string{
+		"no rules":          `{"rules": []}`,
+		"misspelled":        `{"rules": [{"when": {"stauts": ["new"]}, "trending": "at risk"}]}`,
+		"bad template":      `{"rules": [{"when": {}, "comment": "{{.Issue.Key"}]}`,
+		"empty children":    `{"rules": [{"when": {"children": {}}, "trending": "done"}]}`,
+		"percent too large": `{"rules": [{"when": {"children": {"trending": ["done"], "min_percent": 150}}}]}`,
+	} {
+		if _, _, err := loadTrendingRules(writeTrendingRules(t, body)); err == nil {
+			t.Errorf("%s: expected error", name)
+		} else if !strings.Contains(err.Error(), "trending") {
+			t.Errorf("%s: error should name the trending rules: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// trendingUpdate is one pending write of computed trending into the trending custom field.
+type trendingUpdate struct {
+	Key  string
+	From string // current field value ("" when unset)
+	To   string // computed trending
+}
+
+// planTrendingWrites returns the issues (parents and loaded descendants, once per key) whose trending
+// field differs from their computed trending, sorted by key, plus how many already match and how many
+// have no computed trending (none, or "unknown" for unmapped statuses; those are never written).
+func planTrendingWrites(parentIssues []*IssueData) (updates []trendingUpdate, unchanged, noTrending int) {
+	seen := make(map[string]bool)
+	for _, issue := range flattenIssues(parentIssues) {
+		if seen[issue.Key] {
+			continue
+		}
+		seen[issue.Key] = true
+		switch {
+		case issue.Trending == "" || issue.Trending == "unknown":
+			noTrending++
+		case strings.EqualFold(strings.TrimSpace(issue.TrendingField), issue.Trending):
+			unchanged++
+		default:
+			updates = append(updates, trendingUpdate{Key: issue.Key, From: issue.TrendingField, To: issue.Trending})
+		}
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Key < updates[j].Key })
+	return updates, unchanged, noTrending
+}
+
+// trendingFieldFormat is how a value is written into the trending field: option fields take
+// {"value": ...} naming one of their configured options, text fields take the string.
+type trendingFieldFormat struct {
+	isOption bool
+	options  map[string]string // lowercased option -> option value as configured in Jira
+}
+
+// loadTrendingFieldFormat reads the field's schema (and options) from issueKey's edit metadata,
+// which also confirms the field is on the issue's edit screen.
+func (c *JiraClient) loadTrendingFieldFormat(ctx context.Context, issueKey, fieldID string) (trendingFieldFormat, error) {
+	meta, err := c.getJson(ctx, fmt.Sprintf("issue/%s/editmeta", issueKey), nil)
+	if err != nil {
+		return trendingFieldFormat{}, err
+	}
+	field := getMap(getMap(meta, "fields"), fieldID)
+	if field == nil {
+		return trendingFieldFormat{}, fmt.Errorf("field %s is not editable on %s (is it on the edit screen?)", fieldID, issueKey)
+	}
+	switch schemaType := getString(getMap(field, "schema"), "type", ""); schemaType {
+	case "string":
+		return trendingFieldFormat{}, nil
+	case "option":
+		f := trendingFieldFormat{isOption: true, options: map[string]string{}}
+		for _, opt := range getMapList(field, "allowedValues") {
+			if v := getString(opt, "value", ""); v != "" {
+				f.options[strings.ToLower(strings.TrimSpace(v))] = v
+			}
+		}
+		return f, nil
+	default:
+		return trendingFieldFormat{}, fmt.Errorf("field %s has type %q; --write-trending supports text and single-select fields", fieldID, schemaType)
+	}
+}
+
+// trendingFormats loads trendingFieldFormat once per project: the field's type and options can differ
+// between projects (field contexts, edit screens), so one issue's metadata does not hold for a whole
+// JQL result. Safe for concurrent use; writers for different projects never wait on each other.
+type trendingFormats struct {
+	client  *JiraClient
+	fieldID string
+
+	mu        sync.Mutex // guards byProject only
+	byProject map[string]*projectTrendingFormat
+}
+
+// projectTrendingFormat is a project's format, read from the edit metadata of issue key. mu serializes
+// the load; a failed load is not kept, so the project's next issue tries again.
+type projectTrendingFormat struct {
+	mu     sync.Mutex
+	loaded bool
+	key    string
+	format trendingFieldFormat
+}
+
+// valueFor returns the value to PUT on issueKey. When its project's format does not fit (another screen
+// or field context within the project), the issue's own edit metadata is read and tried once.
+func (f *trendingFormats) valueFor(ctx context.Context, issueKey, trending string) (any, error) {
+	project, _, _ := strings.Cut(issueKey, "-")
+	f.mu.Lock()
+	p, ok := f.byProject[project]
+	if !ok {
+		p = &projectTrendingFormat{}
+		f.byProject[project] = p
+	}
+	f.mu.Unlock()
+
+	p.mu.Lock()
+	if !p.loaded {
+		format, err := f.client.loadTrendingFieldFormat(ctx, issueKey, f.fieldID)
+		if err != nil {
+			p.mu.Unlock()
+			return nil, err
+		}
+		p.loaded, p.key, p.format = true, issueKey, format
+	}
+	key, format := p.key, p.format
+	p.mu.Unlock()
+
+	value, err := format.value(trending)
+	if err == nil || key == issueKey {
+		return value, err
+	}
+	if format, err = f.client.loadTrendingFieldFormat(ctx, issueKey, f.fieldID); err != nil {
+		return nil, err
+	}
+	return format.value(trending)
+}
+
+// value returns the field value to PUT for a trending value.
+func (f trendingFieldFormat) value(trending string) (any, error) {
+	if !f.isOption {
+		return trending, nil
+	}
+	option, ok := f.options[strings.ToLower(trending)]
+	if !ok {
+		return nil, fmt.Errorf("no option %q on the trending field", trending)
+	}
+	return map[string]any{"value": option}, nil
+}
+
+// writeTrendingField writes each issue's computed trending into cfg.TrendingStatusFieldName
+// (--write-trending), skipping issues whose field already matches or that have no computed trending.
+// It prints the pending changes, stops there with cfg.DryRun, and otherwise updates issues in parallel
+// (bounded by MaxConcurrent) and prints how many were updated, unchanged, without trending and failed.
+func writeTrendingField(ctx context.Context, client *JiraClient, parentIssues []*IssueData, cfg *ReportConfig) error {
+	fieldName := strings.TrimSpace(cfg.TrendingStatusFieldName)
+	if fieldName == "" {
+		return fmt.Errorf("--write-trending needs JIRA_TRENDING_STATUS_FIELD (the custom field to write)")
+	}
+	fieldID := cfg.CustomFieldNameToID[fieldName]
+	if fieldID == "" {
+		return fmt.Errorf("could not resolve custom field %q (JIRA_TRENDING_STATUS_FIELD)", fieldName)
+	}
+
+	updates, unchanged, noTrending := planTrendingWrites(parentIssues)
+	fmt.Printf("%s (%s): %d to update, %d unchanged, %d without computed trending\n", fieldName, fieldID, len(updates), unchanged, noTrending)
+	for _, u := range updates {
+		fmt.Printf("  %s: %s → %s\n", u.Key, orNone(u.From), u.To)
+	}
+	if cfg.DryRun || len(updates) == 0 {
+		return nil
+	}
+
+	formats := &trendingFormats{client: client, fieldID: fieldID, byProject: map[string]*projectTrendingFormat{}}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		updated int
+		failed  int
+	)
+	sem := make(chan struct{}, client.concurrencyCap())
+	for _, u := range updates {
+		wg.Add(1)
+		go func(u trendingUpdate) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			value, err := formats.valueFor(ctx, u.Key, u.To)
+			if err == nil {
+				err = client.UpdateIssueFields(ctx, u.Key, map[string]any{fieldID: value})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logWarning("Could not update %s on %s: %v", fieldName, u.Key, err)
+				failed++
+				return
+			}
+			updated++
+		}(u)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated %d, unchanged %d, without trending %d, failed %d.\n", updated, unchanged, noTrending, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d trending updates failed", failed, len(updates))
+	}
+	return nil
+}
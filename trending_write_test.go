@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPlanTrendingWrites(t *testing.T) {
+	parents := []*IssueData{
+		{Key: "E-2", Trending: "at risk", TrendingField: "On track", Children: []*IssueData{
+			{Key: "S-1", Trending: "done", TrendingField: "Done"},
+			{Key: "S-2", Trending: "off track"},
+		}},
+		{Key: "E-1", Trending: ""},
+		{Key: "E-4", Trending: "unknown", TrendingField: "On track"},              // unmapped status: never overwrite a real value
+		{Key: "E-3", Children: []*IssueData{{Key: "S-2", Trending: "off track"}}}, // S-2 loaded again as a separate object
+	}
+	updates, unchanged, noTrending := planTrendingWrites(parents)
+	want := []trendingUpdate{{Key: "E-2", From: "On track", To: "at risk"}, {Key: "S-2", To: "off track"}}
+	if len(updates) != len(want) || updates[0] != want[0] || updates[1] != want[1] {
+		t.Errorf("updates = %+v, want %+v (each key once)", updates, want)
+	}
+	if unchanged != 1 || noTrending != 3 {
+		t.Errorf("unchanged = %d, noTrending = %d, want 1 and 3", unchanged, noTrending)
+	}
+}
+
+func TestWriteTrendingField(t *testing.T) {
+	noRetrySleep(t)
+	var (
+		mu   sync.Mutex
+		puts = map[string]any{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/T-1/editmeta"):
+			// another project uses a text field
+			json.NewEncoder(w).Encode(map[string]any{"fields": map[string]any{"customfield_7": map[string]any{
+				"schema": map[string]any{"type": "string"},
+			}}})
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/editmeta"):
+			json.NewEncoder(w).Encode(map[string]any{"fields": map[string]any{"customfield_7": map[string]any{
+				"schema":        map[string]any{"type": "option"},
+				"allowedValues": []any{map[string]any{"value": "On Track"}, map[string]any{"value": "At Risk"}},
+			}}})
+		case r.Method == "PUT":
+			var body struct {
+				Fields map[string]any `json:"fields"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			puts[strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")] = body.Fields["customfield_7"]
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	parents := []*IssueData{
+		{Key: "E-1", Trending: "at risk", TrendingField: "On Track"},
+		{Key: "E-2", Trending: "on track", TrendingField: "On Track"},
+		{Key: "E-3", Trending: "on track"},
+		{Key: "E-4", Trending: "off track"}, // no such option
+		{Key: "T-1", Trending: "off track"},
+	}
+	cfg := &ReportConfig{TrendingStatusFieldName: "Health", CustomFieldNameToID: map[string]string{"Health": "customfield_7"}, WriteTrending: true}
+	client := testJiraClientForServer(ts)
+
+	cfg.DryRun = true
+	if err := writeTrendingField(context.Background(), client, parents, cfg); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(puts) != 0 {
+		t.Fatal("--dry-run must not write")
+	}
+
+	cfg.DryRun = false
+	err := writeTrendingField(context.Background(), client, parents, cfg)
+	if err == nil || !strings.Contains(err.Error(), "1 of 4") {
+		t.Errorf("err = %v, want 1 of 4 updates failed", err)
+	}
+	if len(puts) != 3 {
+		t.Fatalf("puts = %v, want E-1, E-3 and T-1", puts)
+	}
+	if puts["T-1"] != "off track" {
+		t.Errorf("T-1 = %v, want the text value from its own project's field format", puts["T-1"])
+	}
+	for key, want := range map[string]string{"E-1": "At Risk", "E-3": "On Track"} {
+		if got, _ := puts[key].(map[string]any); got["value"] != want {
+			t.Errorf("%s = %v, want option %q", key, puts[key], want)
+		}
+	}
+}
+
+func TestTrendingFormats_failedLoadIsNotCached(t *testing.T) {
+	noRetrySleep(t)
+	var (
+		mu    sync.Mutex
+		loads []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/editmeta")
+		mu.Lock()
+		loads = append(loads, key)
+		mu.Unlock()
+		if key == "E-1" {
+			json.NewEncoder(w).Encode(map[string]any{"fields": map[string]any{}}) // not on E-1's edit screen
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"fields": map[string]any{"customfield_7": map[string]any{
+			"schema": map[string]any{"type": "string"},
+		}}})
+	}))
+	defer ts.Close()
+
+	formats := &trendingFormats{client: testJiraClientForServer(ts), fieldID: "customfield_7", byProject: map[string]*projectTrendingFormat{}}
+	if _, err := formats.valueFor(context.Background(), "E-1", "at risk"); err == nil {
+		t.Error("E-1 without the field on its edit screen should fail")
+	}
+	for _, key := range []string{"E-2", "E-3"} {
+		if v, err := formats.valueFor(context.Background(), key, "at risk"); err != nil || v != "at risk" {
+			t.Errorf("%s = %v, %v", key, v, err)
+		}
+	}
+	if strings.Join(loads, ",") != "E-1,E-2" {
+		t.Errorf("editmeta loads = %v, want E-1 then E-2 (the failure is retried, the success reused)", loads)
+	}
+}
+
+func TestWriteTrendingField_needsResolvedField(t *testing.T) {
+	cfg := &ReportConfig{TrendingStatusFieldName: "Health", WriteTrending: true}
+	if err := writeTrendingField(context.Background(), &JiraClient{}, nil, cfg); err == nil {
+		t.Error("an unresolved trending field should be an error")
+	}
+}